@@ -0,0 +1,178 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// alphabet is the set of runes a rand string draws from. Alphabets of single
+// bytes keep them in the order written so strings can be built from masked
+// bits of a random number, others pick a rune weighted by the size of its
+// range.
+type alphabet struct {
+	bytes  string
+	ranges []runeRange
+	size   int
+	valid  func(rune) bool
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+var alphabets = map[string]alphabet{
+	"lower":     mustAlphabet("a-z"),
+	"upper":     mustAlphabet("A-Z"),
+	"letters":   mustAlphabet("a-zA-Z"),
+	"digits":    mustAlphabet("0-9"),
+	"alnum":     mustAlphabet("a-zA-Z0-9"),
+	"hex":       mustAlphabet("0-9a-f"),
+	"HEX":       mustAlphabet("0-9A-F"),
+	"printable": mustAlphabet(" -~"),
+	"unicode": {
+		ranges: []runeRange{{' ', 0xD7FF}, {0xE000, unicode.MaxRune}},
+		size:   0xD7FF - ' ' + 1 + unicode.MaxRune - 0xE000 + 1,
+		valid:  unicode.IsPrint,
+	},
+}
+
+var defaultAlphabet = alphabets["alnum"]
+
+func mustAlphabet(chars string) alphabet {
+	a, err := parseAlphabet(chars)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// parseAlphabet parses a set of runes such as abc or a-zA-Z0-9. A backslash
+// makes the following rune literal, e.g. \- for a dash.
+func parseAlphabet(chars string) (alphabet, error) {
+	var runes []rune
+	var escaped []bool
+	for i := 0; i < len(chars); {
+		esc := chars[i] == '\\' && i+1 < len(chars)
+		if esc {
+			i++
+		}
+		c, size := utf8.DecodeRuneInString(chars[i:])
+		if c == utf8.RuneError && size == 1 {
+			return alphabet{}, fmt.Errorf("rand chars not valid utf-8 %q", chars)
+		}
+		runes = append(runes, c)
+		escaped = append(escaped, esc)
+		i += size
+	}
+	if len(runes) == 0 {
+		return alphabet{}, errors.New("rand chars empty")
+	}
+	var ranges []runeRange
+	for i := 0; i < len(runes); i++ {
+		rg := runeRange{lo: runes[i], hi: runes[i]}
+		if i+2 < len(runes) && runes[i+1] == '-' && !escaped[i+1] {
+			rg.hi = runes[i+2]
+			i += 2
+		}
+		if rg.lo > rg.hi {
+			return alphabet{}, fmt.Errorf("rand chars range %c-%c reversed", rg.lo, rg.hi)
+		}
+		ranges = append(ranges, rg)
+	}
+	var a alphabet
+	var b strings.Builder
+	var seen [utf8.RuneSelf]bool
+	for _, rg := range ranges {
+		for c := rg.lo; c <= rg.hi && c < utf8.RuneSelf; c++ {
+			if !seen[c] {
+				seen[c] = true
+				b.WriteByte(byte(c))
+			}
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].lo < ranges[j].lo })
+	for _, rg := range ranges {
+		if last := len(a.ranges) - 1; last >= 0 && rg.lo <= a.ranges[last].hi+1 {
+			a.ranges[last].hi = max(a.ranges[last].hi, rg.hi)
+			continue
+		}
+		a.ranges = append(a.ranges, rg)
+	}
+	for _, rg := range a.ranges {
+		a.size += int(rg.hi-rg.lo) + 1
+	}
+	if a.ranges[len(a.ranges)-1].hi >= utf8.RuneSelf {
+		return a, nil
+	}
+	a.bytes = b.String()
+	return a, nil
+}
+
+// pairs returns the ranges as lo, hi pairs the way regexp/syntax stores
+// character classes.
+func (a alphabet) pairs() []rune {
+	res := make([]rune, 0, 2*len(a.ranges))
+	for _, rg := range a.ranges {
+		res = append(res, rg.lo, rg.hi)
+	}
+	return res
+}
+
+func (a alphabet) randString(r *rand.Rand, n int64) string {
+	if a.bytes != "" {
+		return randBytes(r, n, a.bytes)
+	}
+	var b strings.Builder
+	b.Grow(int(n))
+	for i := int64(0); i < n; {
+		c := a.pick(r.Intn(a.size))
+		if a.valid != nil && !a.valid(c) {
+			continue
+		}
+		b.WriteRune(c)
+		i++
+	}
+	return b.String()
+}
+
+func (a alphabet) pick(k int) rune {
+	for _, rg := range a.ranges {
+		if size := int(rg.hi-rg.lo) + 1; k >= size {
+			k -= size
+			continue
+		}
+		return rg.lo + rune(k)
+	}
+	return a.ranges[len(a.ranges)-1].hi
+}
+
+// randBytes builds a string from chars, taking as many indexes from each
+// random number as fit in its 63 bits and skipping those past the end.
+func randBytes(r *rand.Rand, n int64, chars string) string {
+	idxBits := bits.Len(uint(len(chars) - 1))
+	if idxBits == 0 {
+		return strings.Repeat(chars, int(n))
+	}
+	idxMask := int64(1)<<idxBits - 1
+	idxMax := 63 / idxBits
+	b := make([]byte, n)
+	for i, cache, remain := n-1, r.Int63(), idxMax; i >= 0; {
+		if remain == 0 {
+			cache, remain = r.Int63(), idxMax
+		}
+		if idx := int(cache & idxMask); idx < len(chars) {
+			b[i] = chars[idx]
+			i--
+		}
+		cache >>= idxBits
+		remain--
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
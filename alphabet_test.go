@@ -0,0 +1,70 @@
+package gomaker
+
+import (
+	"math/rand"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func Test_parseAlphabet(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		chars string
+		bytes string
+		size  int
+		err   string
+	}{
+		{"literal", "abc", "abc", 3, ""},
+		{"ranges in order", "a-cA-C0-2", "abcABC012", 9, ""},
+		{"duplicates", "abca-c", "abc", 3, ""},
+		{"escaped dash", `a\-c`, "a-c", 3, ""},
+		{"trailing dash", "ab-", "ab-", 3, ""},
+		{"unicode", "а-я", "", 32, ""},
+		{"reversed", "z-a", "", 0, "rand chars range z-a reversed"},
+		{"empty", "", "", 0, "rand chars empty"},
+		{"invalid", "a\xff", "", 0, `rand chars not valid utf-8 "a\xff"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := parseAlphabet(tt.chars)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+			if a.bytes != tt.bytes || a.size != tt.size {
+				t.Errorf("parseAlphabet() = %q %d, want %q %d", a.bytes, a.size, tt.bytes, tt.size)
+			}
+		})
+	}
+}
+
+func Test_alphabet_randString(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	counts := map[rune]int{}
+	cyrillic := mustAlphabet("а-яё")
+	for _, c := range cyrillic.randString(r, 33000) {
+		counts[c]++
+	}
+	if len(counts) != 33 {
+		t.Fatalf("expected 33 runes got %d", len(counts))
+	}
+	for c, n := range counts {
+		if c != 'ё' && (c < 'а' || c > 'я') || n < 700 || n > 1300 {
+			t.Errorf("rune %c drawn %d times", c, n)
+		}
+	}
+	s := alphabets["unicode"].randString(r, 1000)
+	if utf8.RuneCountInString(s) != 1000 {
+		t.Fatalf("expected 1000 runes got %d", utf8.RuneCountInString(s))
+	}
+	for _, c := range s {
+		if !unicode.IsPrint(c) {
+			t.Fatalf("rune %U not printable", c)
+		}
+	}
+	if s := mustAlphabet("x").randString(r, 3); s != "xxx" {
+		t.Errorf("single rune alphabet got %s", s)
+	}
+}
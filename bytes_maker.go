@@ -0,0 +1,89 @@
+package gomaker
+
+import (
+	b64 "encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultEncodedBytes is how many random bytes hex and base64 encode into a
+// string when the tag gives no bytes option.
+const defaultEncodedBytes = 16
+
+// compileBytes compiles hex and base64. Strings get random bytes in that
+// encoding, hex[bytes=8] setting their number. A [N]byte array given the
+// encoded form of N bytes, e.g. hex[00ff] or base64[AP8=], is set to that
+// constant; bare, both fill it with random bytes.
+func compileBytes(tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	if typeOf.Kind() == reflect.String {
+		return compileEncoded(tagValue)
+	}
+	if typeOf.Kind() != reflect.Array || typeOf.Elem().Kind() != reflect.Uint8 {
+		return nil, fmt.Errorf("kind not supported: %s", typeOf.Kind().String())
+	}
+	option := optionValueOf(tagValue)
+	value := strings.TrimPrefix(tagValue, string(option))
+	if value == "" {
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			_, err := r.Read(field.Slice(0, field.Len()).Bytes())
+			return err
+		}, nil
+	}
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("option not available %s", tagValue)
+	}
+	value = value[1 : len(value)-1]
+	var decoded []byte
+	var err error
+	if option == hexa {
+		decoded, err = hex.DecodeString(value)
+	} else {
+		decoded, err = b64.StdEncoding.DecodeString(value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s decode failed: %w", option, err)
+	}
+	if len(decoded) != typeOf.Len() {
+		return nil, fmt.Errorf("%s decoded %d bytes expected %d", option, len(decoded), typeOf.Len())
+	}
+	return func(_ *rand.Rand, _ *scope, field reflect.Value) error {
+		reflect.Copy(field, reflect.ValueOf(decoded))
+		return nil
+	}, nil
+}
+
+// compileEncoded compiles hex[bytes=N] and base64[bytes=N] for strings.
+func compileEncoded(tagValue string) (fillFunc, error) {
+	option := optionValueOf(tagValue)
+	args, err := getArgs(tagValue, string(option))
+	if err != nil {
+		return nil, err
+	}
+	n := defaultEncodedBytes
+	for _, arg := range args {
+		key, val, _ := strings.Cut(arg, "=")
+		if key != "bytes" {
+			return nil, fmt.Errorf("%s option not available %s", option, arg)
+		}
+		if n, err = strconv.Atoi(val); err != nil {
+			return nil, fmt.Errorf("%s bytes: %w", option, err)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("negative %s bytes %d", option, n)
+		}
+	}
+	encode := hex.EncodeToString
+	if option == base64 {
+		encode = b64.StdEncoding.EncodeToString
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		b := make([]byte, n)
+		r.Read(b)
+		field.SetString(encode(b))
+		return nil
+	}, nil
+}
@@ -0,0 +1,230 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var distributionPattern = regexp.MustCompile(`^(\w+)\((.*)\)$`)
+
+// maxSamples bounds how often a sample outside [min, max) is redrawn before
+// it is clamped into the range.
+const maxSamples = 100
+
+// distribution draws a value given the range start and step. Continuous
+// distributions ignore them, exp starts at min and zipf counts steps.
+type distribution interface {
+	sample(r *rand.Rand, min, step float64) float64
+}
+
+type uniform struct {
+	max float64
+}
+
+func (d uniform) sample(r *rand.Rand, min, _ float64) float64 {
+	return min + r.Float64()*(d.max-min)
+}
+
+type normal struct {
+	mean, stddev float64
+}
+
+func (d normal) sample(r *rand.Rand, _, _ float64) float64 {
+	return d.mean + r.NormFloat64()*d.stddev
+}
+
+type exponential struct {
+	rate float64
+}
+
+func (d exponential) sample(r *rand.Rand, min, _ float64) float64 {
+	return min + r.ExpFloat64()/d.rate
+}
+
+type logNormal struct {
+	mu, sigma float64
+}
+
+func (d logNormal) sample(r *rand.Rand, _, _ float64) float64 {
+	return math.Exp(d.mu + r.NormFloat64()*d.sigma)
+}
+
+// zipf samples by rejection-inversion, as rand.Zipf does, with the constants
+// computed once by newZipf instead of on every draw.
+type zipf struct {
+	imax, v, q, s           float64
+	oneMinusQ, oneMinusQInv float64
+	hxm, hx0MinusHxm        float64
+}
+
+func newZipf(s, v float64, imax uint64) zipf {
+	z := zipf{imax: float64(imax), v: v, q: s}
+	z.oneMinusQ = 1 - z.q
+	z.oneMinusQInv = 1 / z.oneMinusQ
+	z.hxm = z.h(z.imax + 0.5)
+	z.hx0MinusHxm = z.h(0.5) - math.Exp(math.Log(z.v)*-z.q) - z.hxm
+	z.s = 1 - z.hinv(z.h(1.5)-math.Exp(-z.q*math.Log(z.v+1)))
+	return z
+}
+
+func (z zipf) h(x float64) float64 {
+	return math.Exp(z.oneMinusQ*math.Log(z.v+x)) * z.oneMinusQInv
+}
+
+func (z zipf) hinv(x float64) float64 {
+	return math.Exp(z.oneMinusQInv*math.Log(z.oneMinusQ*x)) - z.v
+}
+
+func (z zipf) sample(r *rand.Rand, min, step float64) float64 {
+	var k float64
+	for {
+		ur := z.hxm + r.Float64()*z.hx0MinusHxm
+		x := z.hinv(ur)
+		k = math.Floor(x + 0.5)
+		if k-x <= z.s || ur >= z.h(k+0.5)-math.Exp(-math.Log(k+z.v)*z.q) {
+			break
+		}
+	}
+	return min + k*step
+}
+
+type bucket struct {
+	min, max float64
+	weight   float64
+}
+
+// buckets picks a bucket by weight, then a uniform value inside it.
+type buckets struct {
+	buckets    []bucket
+	cumulative []float64
+}
+
+func (d buckets) sample(r *rand.Rand, _, _ float64) float64 {
+	pick := r.Float64() * d.cumulative[len(d.cumulative)-1]
+	i := sort.SearchFloat64s(d.cumulative, pick)
+	if i == len(d.buckets) {
+		i--
+	}
+	b := d.buckets[i]
+	return b.min + r.Float64()*(b.max-b.min)
+}
+
+// parseDistribution parses normal(mean,stddev), exp(rate),
+// lognormal(mu,sigma), zipf(s,v) and buckets(min:max:weight,...). min, max
+// and step are the parsed rand bounds.
+func parseDistribution(value string, min, max, step float64) (distribution, error) {
+	matches := distributionPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, fmt.Errorf("dist not recognized %s", value)
+	}
+	name, args := matches[1], strings.Split(matches[2], ",")
+	if name == "buckets" {
+		return parseBuckets(args)
+	}
+	params := make([]float64, len(args))
+	for i, arg := range args {
+		p, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+		if err != nil {
+			return nil, fmt.Errorf("dist %s: %w", name, err)
+		}
+		params[i] = p
+	}
+	want := map[string]int{"normal": 2, "exp": 1, "lognormal": 2, "zipf": 2}
+	n, ok := want[name]
+	if !ok {
+		return nil, fmt.Errorf("dist not available %s", name)
+	}
+	if len(params) != n {
+		return nil, fmt.Errorf("dist %s expects %d arguments got %d", name, n, len(params))
+	}
+	switch name {
+	case "normal":
+		if params[1] <= 0 {
+			return nil, errors.New("dist normal stddev not positive")
+		}
+		return normal{mean: params[0], stddev: params[1]}, nil
+	case "exp":
+		if params[0] <= 0 {
+			return nil, errors.New("dist exp rate not positive")
+		}
+		return exponential{rate: params[0]}, nil
+	case "lognormal":
+		if params[1] <= 0 {
+			return nil, errors.New("dist lognormal sigma not positive")
+		}
+		return logNormal{mu: params[0], sigma: params[1]}, nil
+	default:
+		if params[0] <= 1 || params[1] < 1 {
+			return nil, errors.New("dist zipf expects s > 1 and v >= 1")
+		}
+		if step <= 0 {
+			step = 1
+		}
+		imax := uint64(math.MaxUint64)
+		if steps := math.Ceil((max-min)/step) - 1; steps < float64(math.MaxUint64) {
+			imax = uint64(math.Max(steps, 0))
+		}
+		return newZipf(params[0], params[1], imax), nil
+	}
+}
+
+func parseBuckets(args []string) (distribution, error) {
+	d := buckets{}
+	var total float64
+	for _, arg := range args {
+		parts := strings.Split(strings.TrimSpace(arg), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("dist buckets expects min:max:weight got %s", arg)
+		}
+		var b bucket
+		for i, dst := range []*float64{&b.min, &b.max, &b.weight} {
+			p, err := strconv.ParseFloat(parts[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("dist buckets: %w", err)
+			}
+			*dst = p
+		}
+		if b.min > b.max {
+			return nil, errors.New("dist buckets min bigger then max")
+		}
+		if b.weight <= 0 {
+			return nil, errors.New("dist buckets weight not positive")
+		}
+		total += b.weight
+		d.buckets = append(d.buckets, b)
+		d.cumulative = append(d.cumulative, total)
+	}
+	return d, nil
+}
+
+// sampleIn draws from d until the value falls in [min, max), clamping the
+// last draw if none does. A range with min equal to max only holds min.
+func sampleIn(r *rand.Rand, d distribution, min, max, step float64) float64 {
+	if min == max {
+		return min
+	}
+	var x float64
+	for i := 0; i < maxSamples; i++ {
+		if x = d.sample(r, min, step); x >= min && x < max {
+			return x
+		}
+	}
+	if x < min || math.IsNaN(x) {
+		return min
+	}
+	return math.Nextafter(max, min)
+}
+
+// quantize moves x down to the closest min+k*step.
+func quantize(x, min, step float64) float64 {
+	if step <= 0 {
+		return x
+	}
+	return min + math.Floor((x-min)/step)*step
+}
@@ -0,0 +1,145 @@
+package gomaker
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func Test_parseDistribution(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		arg  string
+		err  string
+	}{
+		{"normal", "normal(50,10)", ""},
+		{"exp", "exp(0.5)", ""},
+		{"lognormal", "lognormal(1, 0.5)", ""},
+		{"zipf", "zipf(1.5,1)", ""},
+		{"buckets", "buckets(1:10:5,10:100:1)", ""},
+		{"unknown", "poisson(3)", "dist not available poisson"},
+		{"syntax", "normal", "dist not recognized normal"},
+		{"arity", "normal(1)", "dist normal expects 2 arguments got 1"},
+		{"stddev", "normal(1,0)", "dist normal stddev not positive"},
+		{"zipf s", "zipf(1,1)", "dist zipf expects s > 1 and v >= 1"},
+		{"bucket weight", "buckets(1:2:0)", "dist buckets weight not positive"},
+		{"bucket syntax", "buckets(1:2)", "dist buckets expects min:max:weight got 1:2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDistribution(tt.arg, 0, 100, 1)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func Test_distributions(t *testing.T) {
+	t.Parallel()
+	const n = 20000
+	stats := func(d distribution, in floatRange) (mean, stddev float64, values []float64) {
+		r := rand.New(rand.NewSource(1))
+		in.dist = d
+		values = make([]float64, n)
+		for i := range values {
+			values[i] = randFloat64(r, in)
+			if values[i] < in.min || values[i] >= in.max {
+				t.Fatalf("%v out of range", values[i])
+			}
+			mean += values[i]
+		}
+		mean /= n
+		for _, v := range values {
+			stddev += (v - mean) * (v - mean)
+		}
+		return mean, math.Sqrt(stddev / n), values
+	}
+	near := func(name string, got, want, tolerance float64) {
+		if math.Abs(got-want) > tolerance {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	unbounded := floatRange{min: -math.MaxFloat64, max: math.MaxFloat64}
+
+	mean, stddev, _ := stats(normal{mean: 50, stddev: 10}, unbounded)
+	near("normal mean", mean, 50, 0.5)
+	near("normal stddev", stddev, 10, 0.5)
+
+	mean, _, _ = stats(exponential{rate: 0.5}, floatRange{min: 0, max: math.MaxFloat64})
+	near("exp mean", mean, 2, 0.1)
+
+	_, _, values := stats(logNormal{mu: 1, sigma: 0.5}, floatRange{min: 0, max: math.MaxFloat64})
+	below := 0
+	for _, v := range values {
+		if v < math.E {
+			below++
+		}
+	}
+	near("lognormal median share", float64(below)/n, 0.5, 0.02)
+
+	d, err := parseDistribution("buckets(0:10:3,100:110:1)", 0, 200, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, values = stats(d, floatRange{min: 0, max: 200})
+	low := 0
+	for _, v := range values {
+		if v < 10 {
+			low++
+		} else if v < 100 || v >= 110 {
+			t.Fatalf("bucket value %v outside buckets", v)
+		}
+	}
+	near("buckets low share", float64(low)/n, 0.75, 0.02)
+
+	d, err = parseDistribution("zipf(2,1)", 10, 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, values = stats(d, floatRange{min: 10, max: 20, step: 2})
+	counts := map[float64]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+	if len(counts) > 5 || counts[10] < counts[12] || counts[12] < counts[14] {
+		t.Errorf("zipf counts %v not decreasing over steps", counts)
+	}
+
+	_, _, values = stats(normal{mean: 5, stddev: 3}, floatRange{min: 0, max: 10, step: 0.5})
+	for _, v := range values {
+		if math.Mod(v, 0.5) != 0 {
+			t.Fatalf("%v not quantized to step", v)
+		}
+	}
+}
+
+func Test_zipf(t *testing.T) {
+	t.Parallel()
+	for _, p := range []struct {
+		s, v float64
+		imax uint64
+	}{{1.5, 1, 10}, {2, 3, 1000}, {1.1, 1, math.MaxUint64}} {
+		z := newZipf(p.s, p.v, p.imax)
+		r, ref := rand.New(rand.NewSource(8)), rand.New(rand.NewSource(8))
+		want := rand.NewZipf(ref, p.s, p.v, p.imax)
+		for i := 0; i < 1000; i++ {
+			if got, w := z.sample(r, 0, 1), float64(want.Uint64()); got != w {
+				t.Fatalf("zipf(%v,%v) draw %d = %v, rand.Zipf gave %v", p.s, p.v, i, got, w)
+			}
+		}
+	}
+}
+
+func BenchmarkZipf(b *testing.B) {
+	d, err := parseDistribution("zipf(1.5,1)", 0, 1e6, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(9))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sampleIn(r, d, 0, 1e6, 1)
+	}
+}
@@ -0,0 +1,219 @@
+package gomaker
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//go:embed data
+var dataFS embed.FS
+
+// Locale is a pack of fake data, lists keyed by name such as first_names,
+// cities or phones. Phone and zip entries are patterns whose # become digits,
+// street_formats entries place {number} and {street}.
+type Locale map[string][]string
+
+const defaultLocale = "en_US"
+
+var locales = mustLoadLocales(dataFS, "data")
+
+func mustLoadLocales(fsys fs.FS, dir string) map[string]Locale {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	res := make(map[string]Locale, len(entries))
+	for _, entry := range entries {
+		if res[entry.Name()], err = LoadLocale(fsys, path.Join(dir, entry.Name())); err != nil {
+			panic(err)
+		}
+	}
+	return res
+}
+
+// LoadLocale reads a Locale from the .txt files in dir, one list per file
+// named after it and one entry per line, e.g. cities.txt for fake[city].
+func LoadLocale(fsys fs.FS, dir string) (Locale, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	l := Locale{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				l[name] = append(l[name], line)
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l Locale) pick(r *rand.Rand, list string) string {
+	return l[list][r.Intn(len(l[list]))]
+}
+
+// digits replaces every # in pattern with a random digit.
+func digits(r *rand.Rand, pattern string) string {
+	b := []byte(pattern)
+	for i, c := range b {
+		if c == '#' {
+			b[i] = byte('0' + r.Intn(10))
+		}
+	}
+	return string(b)
+}
+
+// asciiFold spells the letters of the built in locales in ASCII for email
+// addresses and drops anything else outside ASCII.
+var asciiFold = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss",
+	"č", "c", "ć", "c", "š", "s", "ž", "z", "đ", "dj", "Č", "C", "Ć", "C", "Š", "S", "Ž", "Z", "Đ", "Dj",
+)
+
+func emailLocal(s string) string {
+	s = strings.ToLower(asciiFold.Replace(s))
+	return strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' {
+			return c
+		}
+		return -1
+	}, s)
+}
+
+type fakeArgs struct {
+	words int
+}
+
+// faker is a fake provider and the locale lists it draws from.
+type faker struct {
+	lists []string
+	opts  map[string]bool
+	fake  func(r *rand.Rand, l Locale, args fakeArgs) string
+}
+
+var fakers = map[string]faker{
+	"firstname": {lists: []string{"first_names"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return l.pick(r, "first_names")
+	}},
+	"lastname": {lists: []string{"last_names"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return l.pick(r, "last_names")
+	}},
+	"name": {lists: []string{"first_names", "last_names"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return l.pick(r, "first_names") + " " + l.pick(r, "last_names")
+	}},
+	"email": {lists: []string{"first_names", "last_names", "domains"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		local := emailLocal(l.pick(r, "first_names") + "." + l.pick(r, "last_names"))
+		if n := r.Intn(200); n < 100 {
+			local += strconv.Itoa(n)
+		}
+		return local + "@" + l.pick(r, "domains")
+	}},
+	"street": {lists: []string{"streets", "street_formats"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return strings.NewReplacer(
+			"{number}", strconv.Itoa(1+r.Intn(9999)),
+			"{street}", l.pick(r, "streets"),
+		).Replace(l.pick(r, "street_formats"))
+	}},
+	"city": {lists: []string{"cities"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return l.pick(r, "cities")
+	}},
+	"zip": {lists: []string{"zips"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return digits(r, l.pick(r, "zips"))
+	}},
+	"phone": {lists: []string{"phones"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return digits(r, l.pick(r, "phones"))
+	}},
+	"company": {lists: []string{"last_names", "company_suffixes"}, fake: func(r *rand.Rand, l Locale, _ fakeArgs) string {
+		return l.pick(r, "last_names") + " " + l.pick(r, "company_suffixes")
+	}},
+	"lorem": {lists: []string{"lorem"}, opts: map[string]bool{"words": true}, fake: func(r *rand.Rand, l Locale, args fakeArgs) string {
+		words := make([]string, args.words)
+		for i := range words {
+			words[i] = l.pick(r, "lorem")
+		}
+		return strings.Join(words, " ")
+	}},
+}
+
+// compileFake parses fake[provider;key=value], e.g. fake[email] or
+// fake[lorem;words=5]. Values come from the locale option, else locale, with
+// packs registered on the Maker taking precedence over the built in ones.
+func compileFake(tagValue string, typeOf reflect.Type, locale string, packs map[string]Locale) (fillFunc, error) {
+	if kind := typeOf.Kind(); kind != reflect.String {
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	f, args, err := getFakeOptions(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	if args.locale != "" {
+		locale = args.locale
+	}
+	l, ok := packs[locale]
+	if !ok {
+		if l, ok = locales[locale]; !ok {
+			return nil, fmt.Errorf("locale not available %s", locale)
+		}
+	}
+	for _, list := range f.lists {
+		if len(l[list]) == 0 {
+			return nil, fmt.Errorf("locale %s missing list %s", locale, list)
+		}
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		field.SetString(f.fake(r, l, args.fakeArgs))
+		return nil
+	}, nil
+}
+
+type fakeOptions struct {
+	fakeArgs
+	locale string
+}
+
+func getFakeOptions(value string) (faker, fakeOptions, error) {
+	args, err := getArgs(value, string(fake))
+	if err != nil {
+		return faker{}, fakeOptions{}, err
+	}
+	if len(args) == 0 || args[0] == "" {
+		return faker{}, fakeOptions{}, fmt.Errorf("fake expects a provider got %s", value)
+	}
+	f, ok := fakers[args[0]]
+	if !ok {
+		return faker{}, fakeOptions{}, fmt.Errorf("fake provider not available %s", args[0])
+	}
+	res := fakeOptions{fakeArgs: fakeArgs{words: 5}}
+	for _, arg := range args[1:] {
+		key, val, _ := strings.Cut(arg, "=")
+		switch {
+		case key == "locale":
+			res.locale = val
+		case !f.opts[key]:
+			return faker{}, fakeOptions{}, fmt.Errorf("fake option not available %s", arg)
+		default:
+			if res.words, err = strconv.Atoi(val); err != nil {
+				return faker{}, fakeOptions{}, fmt.Errorf("fake words: %w", err)
+			}
+			if res.words < 0 {
+				return faker{}, fakeOptions{}, fmt.Errorf("negative fake words %d", res.words)
+			}
+		}
+	}
+	return f, res, nil
+}
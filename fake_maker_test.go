@@ -0,0 +1,60 @@
+package gomaker
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func Test_getFakeOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		arg   string
+		words int
+		err   string
+	}{
+		{"provider", "fake[email]", 5, ""},
+		{"words", "fake[lorem;words=12]", 12, ""},
+		{"locale", "fake[lorem;locale=de_DE;words=3]", 3, ""},
+		{"bare", "fake", 0, "fake expects a provider got fake"},
+		{"unknown", "fake[ssn]", 0, "fake provider not available ssn"},
+		{"option on provider", "fake[city;words=2]", 0, "fake option not available words=2"},
+		{"bad words", "fake[lorem;words=x]", 0, `fake words: strconv.Atoi: parsing "x": invalid syntax`},
+		{"negative words", "fake[lorem;words=-1]", 0, "negative fake words -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, args, err := getFakeOptions(tt.arg)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+			if args.words != tt.words {
+				t.Errorf("getFakeOptions() words = %d, want %d", args.words, tt.words)
+			}
+		})
+	}
+}
+
+func Test_LoadLocale(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"pack/cities.txt": {Data: []byte("Belgrade\n  Novi Sad \n\nNiš\n")},
+		"pack/README.md":  {Data: []byte("ignored")},
+	}
+	l, err := LoadLocale(fsys, "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 1 || len(l["cities"]) != 3 || l["cities"][1] != "Novi Sad" {
+		t.Errorf("LoadLocale() = %v", l)
+	}
+	for locale, l := range locales {
+		for provider, f := range fakers {
+			for _, list := range f.lists {
+				if len(l[list]) == 0 {
+					t.Errorf("locale %s lacks %s for %s", locale, list, provider)
+				}
+			}
+		}
+	}
+}
@@ -2,62 +2,179 @@ package gomaker
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
-	"strings"
 )
 
-func fillFuncSimple(funcMap map[string]func() any, field reflect.Value, tagValue string) error {
-	kind := field.Kind()
-	funcName := getFuncName(tagValue)
-	fn, found := funcMap[funcName]
+// Context is passed to a Generator. Rand is the random stream of the current
+// call, so values drawn from it are reproducible with WithSeed, and Value is
+// the value being filled.
+type Context struct {
+	Rand  *rand.Rand
+	Value reflect.Value
+}
+
+// Generator returns the value for func[name;arg1;arg2], receiving the
+// arguments after the name.
+type Generator func(ctx Context, args ...string) (any, error)
+
+// compileFunc compiles func[name;args] for typeOf. A result the whole field
+// takes is set as is; otherwise it fills the value a pointer points to or the
+// first element of a slice or array, and every further element calls the
+// generator again. len and nil modifiers apply as for other generators.
+func (m *Maker) compileFunc(spec tagSpec, typeOf reflect.Type) (fillFunc, error) {
+	funcName, args, err := getFuncArgs(spec.gen)
+	if err != nil {
+		return nil, err
+	}
+	fn, found := m.funcMap[funcName]
 	if !found {
-		return fmt.Errorf("map missing fn %s", funcName)
-	}
-	switch kind {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		res, ok := fn().(int64)
-		if !ok {
-			return fmt.Errorf("expected int64 got %v", reflect.TypeOf(fn()).Name())
-		}
-		field.SetInt(res)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		res, ok := fn().(uint64)
-		if !ok {
-			return fmt.Errorf("expected uint64 got %v", reflect.TypeOf(fn()).Name())
-		}
-		field.SetUint(res)
-	case reflect.Float32, reflect.Float64:
-		res, ok := fn().(float64)
-		if !ok {
-			return fmt.Errorf("expected float64 got %v", reflect.TypeOf(fn()).Name())
-		}
-		field.SetFloat(res)
-	case reflect.Complex64, reflect.Complex128:
-		res, ok := fn().(complex128)
-		if !ok {
-			return fmt.Errorf("expected complex128 got %v", reflect.TypeOf(fn()).Name())
-		}
-		field.SetComplex(res)
-	case reflect.String:
-		res, ok := fn().(string)
-		if !ok {
-			return fmt.Errorf("expected string got %v", reflect.TypeOf(fn()).Name())
-		}
-		field.SetString(res)
-	case reflect.Bool:
-		res, ok := fn().(bool)
-		if !ok {
-			return fmt.Errorf("expected bool got %v", reflect.TypeOf(fn()).Name())
-		}
-		field.SetBool(res)
+		return nil, fmt.Errorf("map missing fn %s", funcName)
+	}
+	length, chance := m.length, m.nilChance
+	for key, arg := range spec.mods {
+		switch key {
+		case "len":
+			length, err = parseLength(arg)
+		case "nil":
+			chance, err = parseNilChance(arg)
+		default:
+			err = fmt.Errorf("modifiers not supported on kind: %s", typeOf.Kind().String())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := length.Validate(); err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		f := funcFill{r: r, fn: fn, args: args, length: length, chance: chance}
+		if err := f.fill(field, nil, false); err != nil {
+			return fmt.Errorf("fn %s: %w", funcName, err)
+		}
+		return nil
+	}, nil
+}
+
+type funcFill struct {
+	r      *rand.Rand
+	fn     Generator
+	args   []string
+	length lengthRange
+	chance float64
+}
+
+// fill sets field from res when pending, else from a fresh result.
+func (f funcFill) fill(field reflect.Value, res any, pending bool) error {
+	if field.Kind() == reflect.Pointer && f.chance > 0 && f.r.Float64() < f.chance {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if !pending {
+		var err error
+		if res, err = f.fn(Context{Rand: f.r, Value: field}, f.args...); err != nil {
+			return err
+		}
+	}
+	if res == nil || fits(reflect.TypeOf(res), field.Type()) {
+		return assignResult(field, res)
+	}
+	switch field.Kind() {
+	case reflect.Pointer:
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return f.fill(field.Elem(), res, true)
+	case reflect.Slice, reflect.Array:
+		if field.Kind() == reflect.Slice && field.Len() == 0 {
+			n := f.length.pick(f.r)
+			field.Set(reflect.MakeSlice(field.Type(), n, n))
+		}
+		for i := 0; i < field.Len(); i++ {
+			if err := f.fill(field.Index(i), res, i == 0); err != nil {
+				return err
+			}
+		}
+		return nil
 	default:
-		return fmt.Errorf("kind not supported: %s", kind.String())
+		return assignResult(field, res)
 	}
+}
+
+// fits reports whether assignResult may set a value of type v to type t.
+func fits(v, t reflect.Type) bool {
+	return v.AssignableTo(t) || v.ConvertibleTo(t) && !(isNumber(v.Kind()) && t.Kind() == reflect.String)
+}
+
+// assignResult sets field to value, converting it when the types differ, so
+// an int result fills an int64 field and a []string one a named slice type.
+// Numbers must convert without loss and do not convert to strings.
+func assignResult(field reflect.Value, value any) error {
+	typeOf := field.Type()
+	if value == nil {
+		switch typeOf.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+			field.Set(reflect.Zero(typeOf))
+			return nil
+		}
+		return fmt.Errorf("cannot assign nil to %s", typeOf)
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(typeOf):
+		field.Set(v)
+		return nil
+	case !v.CanConvert(typeOf) || isNumber(v.Kind()) && typeOf.Kind() == reflect.String:
+		return fmt.Errorf("cannot assign %s to %s", v.Type(), typeOf)
+	}
+	res := v.Convert(typeOf)
+	if isNumber(v.Kind()) && isNumber(typeOf.Kind()) {
+		lossy := !res.Convert(v.Type()).Equal(v) || negative(v) != negative(res)
+		switch {
+		case isFloat(v.Kind()) && isFloat(typeOf.Kind()):
+			lossy = field.OverflowFloat(v.Float())
+		case isComplex(v.Kind()) && isComplex(typeOf.Kind()):
+			lossy = field.OverflowComplex(v.Complex())
+		}
+		if lossy {
+			return fmt.Errorf("%s %v does not fit %s", v.Type(), v, typeOf)
+		}
+	}
+	field.Set(res)
 	return nil
 }
 
-func getFuncName(value string) string {
-	value = strings.TrimPrefix(value, "func[")
-	value = strings.TrimSuffix(value, "]")
-	return value
+func negative(v reflect.Value) bool {
+	switch {
+	case v.CanInt():
+		return v.Int() < 0
+	case v.CanFloat():
+		return v.Float() < 0
+	}
+	return false
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Complex128
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isComplex(kind reflect.Kind) bool {
+	return kind == reflect.Complex64 || kind == reflect.Complex128
+}
+
+// getFuncArgs splits func[name;arg1;arg2] into the name and arguments.
+func getFuncArgs(value string) (string, []string, error) {
+	args, err := getArgs(value, string(fc))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(args) == 0 || args[0] == "" {
+		return "", nil, fmt.Errorf("func expects a name got %s", value)
+	}
+	return args[0], args[1:], nil
 }
@@ -0,0 +1,72 @@
+package gomaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_getFuncArgs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		arg  string
+		name string
+		args []string
+		err  string
+	}{
+		{"func[id]", "id", []string{}, ""},
+		{"func[between;1;10]", "between", []string{"1", "10"}, ""},
+		{"func[pick;;a]", "pick", []string{"", "a"}, ""},
+		{"func", "", nil, "func expects a name got func"},
+		{"func[;a]", "", nil, "func expects a name got func[;a]"},
+		{"func(id)", "", nil, "option not available func(id)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			name, args, err := getFuncArgs(tt.arg)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+			if name != tt.name || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("getFuncArgs() = %s %v, want %s %v", name, args, tt.name, tt.args)
+			}
+		})
+	}
+}
+
+func Test_assignResult(t *testing.T) {
+	t.Parallel()
+	type celsius float64
+	type names []string
+	tests := []struct {
+		name  string
+		value any
+		into  any
+		want  any
+		err   string
+	}{
+		{"int to int64", 7, int64(0), int64(7), ""},
+		{"int32 to uint8", int32(200), uint8(0), uint8(200), ""},
+		{"float to named", 21.5, celsius(0), celsius(21.5), ""},
+		{"whole float to int", 3.0, 0, 3, ""},
+		{"slice to named", []string{"a"}, names(nil), names{"a"}, ""},
+		{"bytes to string", []byte("ab"), "", "ab", ""},
+		{"nil to pointer", nil, (*int)(nil), (*int)(nil), ""},
+		{"overflow", 300, int8(0), int8(0), "int 300 does not fit int8"},
+		{"fraction", 3.5, 0, 0, "float64 3.5 does not fit int"},
+		{"negative", -1, uint(0), uint(0), "int -1 does not fit uint"},
+		{"int to string", 65, "", "", "cannot assign int to string"},
+		{"nil to int", nil, 0, 0, "cannot assign nil to int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := reflect.New(reflect.TypeOf(tt.into)).Elem()
+			err := assignResult(field, tt.value)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(field.Interface(), tt.want) {
+				t.Errorf("assignResult() = %v, want %v", field.Interface(), tt.want)
+			}
+		})
+	}
+}
@@ -2,9 +2,11 @@ package gomaker
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,20 +15,60 @@ var tag = "gomaker"
 type option string
 
 const (
-	random option = "rand"
-	regex  option = "regex"
-	rel    option = "rel"
-	fc     option = "func"
+	random  option = "rand"
+	regex   option = "regex"
+	rel     option = "rel"
+	fc      option = "func"
+	hexa    option = "hex"
+	base64  option = "base64"
+	tm      option = "time"
+	dur     option = "dur"
+	fake    option = "fake"
+	uuid    option = "uuid"
+	ulid    option = "ulid"
+	ipa     option = "ip"
+	macAddr option = "mac"
+	host    option = "hostname"
+	uri     option = "url"
+	oneof   option = "oneof"
 )
 
+// Maker is safe for concurrent use once created. Every call draws from its own
+// random stream, the n-th call since New or Reset always getting the n-th
+// stream derived from the seed, so a sequence of calls is reproducible.
+// Functions from WithFuncMap and WithGenerators may run concurrently.
 type Maker struct {
-	seed    int64
-	funcMap map[string]func() any
-	fields  map[string]any
+	seed      int64
+	funcMap   map[string]Generator
+	alphabets map[string]string
+	locale    string
+	universe  string
+	repeat    int
+	maxLen    int
+	locales   map[string]Locale
+	fields    map[string]any
+	length    lengthRange
+	nilChance float64
+	now       func() time.Time
+	calls     *atomic.Uint64
+	cache     *planCache
+	regexes   *regexCache
 }
 
 func New(options ...func(maker *Maker)) *Maker {
-	m := &Maker{seed: time.Now().Unix(), funcMap: map[string]func() any{}}
+	m := &Maker{
+		seed:     time.Now().Unix(),
+		funcMap:  map[string]Generator{},
+		length:   defaultLength,
+		locale:   defaultLocale,
+		universe: defaultUniverse,
+		repeat:   defaultRepeat,
+		maxLen:   defaultMaxLen,
+		now:      time.Now,
+		calls:    new(atomic.Uint64),
+		cache:    &planCache{plans: map[reflect.Type]*plan{}},
+		regexes:  &regexCache{progs: map[regexKey]*regexProg{}},
+	}
 	for _, opt := range options {
 		opt(m)
 	}
@@ -39,9 +81,115 @@ func WithSeed(seed int64) func(maker *Maker) {
 	}
 }
 
+// WithFuncMap registers functions taking no arguments for func[name].
 func WithFuncMap(f map[string]func() any) func(maker *Maker) {
+	generators := make(map[string]Generator, len(f))
+	for name, fn := range f {
+		fn := fn
+		generators[name] = func(Context, ...string) (any, error) {
+			return fn(), nil
+		}
+	}
+	return WithGenerators(generators)
+}
+
+// WithGenerators registers generators for func[name;arg1;arg2].
+func WithGenerators(g map[string]Generator) func(maker *Maker) {
+	return func(maker *Maker) {
+		funcMap := make(map[string]Generator, len(maker.funcMap)+len(g))
+		for name, fn := range maker.funcMap {
+			funcMap[name] = fn
+		}
+		for name, fn := range g {
+			funcMap[name] = fn
+		}
+		maker.funcMap = funcMap
+	}
+}
+
+// WithAlphabet registers chars, e.g. "a-f0-9", as an alphabet rand strings
+// can name with alphabet=name.
+func WithAlphabet(name, chars string) func(maker *Maker) {
+	return func(maker *Maker) {
+		alphabets := make(map[string]string, len(maker.alphabets)+1)
+		for n, c := range maker.alphabets {
+			alphabets[n] = c
+		}
+		alphabets[name] = chars
+		maker.alphabets = alphabets
+	}
+}
+
+// WithLocale picks the locale fake values come from, en_US by default. de_DE
+// and sr_RS are built in as well.
+func WithLocale(locale string) func(maker *Maker) {
+	return func(maker *Maker) {
+		maker.locale = locale
+	}
+}
+
+// WithLocalePack registers a locale, or replaces a built in one, for
+// WithLocale and the locale option of fake.
+func WithLocalePack(name string, pack Locale) func(maker *Maker) {
+	return func(maker *Maker) {
+		packs := make(map[string]Locale, len(maker.locales)+1)
+		for n, l := range maker.locales {
+			packs[n] = l
+		}
+		packs[name] = pack
+		maker.locales = packs
+	}
+}
+
+// WithRegexUniverse sets the characters, e.g. "a-zA-Z0-9", that . and negated
+// classes such as [^a-z] or \D in regex draw from, printable ASCII by default.
+// Classes listing their characters are kept as written.
+func WithRegexUniverse(chars string) func(maker *Maker) {
 	return func(maker *Maker) {
-		maker.funcMap = f
+		maker.universe = chars
+	}
+}
+
+// WithRegexRepeat sets how many times more than their minimum *, + and {n,}
+// may repeat in regex, 10 by default. The repeat modifier overrides it per
+// tag, e.g. regex[a+] repeat[3].
+func WithRegexRepeat(max int) func(maker *Maker) {
+	return func(maker *Maker) {
+		maker.repeat = max
+	}
+}
+
+// WithRegexMaxLen sets the most characters a regex value may have, 65536 by
+// default, generating a longer one being an error. 0 removes the limit.
+func WithRegexMaxLen(max int) func(maker *Maker) {
+	return func(maker *Maker) {
+		maker.maxLen = max
+	}
+}
+
+// WithSliceLen sets the length range, inclusive, of slices gomaker allocates
+// when the tag has no len modifier.
+func WithSliceLen(min, max int) func(maker *Maker) {
+	return func(maker *Maker) {
+		maker.length = lengthRange{min: min, max: max}
+	}
+}
+
+// WithNilChance sets the probability, between 0 and 1, of leaving pointers nil
+// when the tag has no nil modifier.
+func WithNilChance(chance float64) func(maker *Maker) {
+	return func(maker *Maker) {
+		maker.nilChance = chance
+	}
+}
+
+// WithNow fixes the instant that relative time bounds such as now or -30d
+// refer to, making them reproducible.
+func WithNow(now time.Time) func(maker *Maker) {
+	return func(maker *Maker) {
+		maker.now = func() time.Time {
+			return now
+		}
 	}
 }
 
@@ -51,121 +199,181 @@ func WithFieldsMapping(f map[string]any) func(maker *Maker) {
 	}
 }
 
+// Reset rewinds the Maker so the following calls repeat the data produced
+// since New.
+func (m *Maker) Reset() {
+	m.calls.Store(0)
+}
+
+// Fork returns a Maker with the same options whose random streams are derived
+// from the seed and name only, independent of calls made on m.
+func (m *Maker) Fork(name string) *Maker {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	f := *m
+	f.seed = int64(mix(uint64(m.seed) ^ h.Sum64()))
+	f.calls = new(atomic.Uint64)
+	return &f
+}
+
 func (m *Maker) Fill(model any) error {
 	if reflect.TypeOf(model).Kind() != reflect.Pointer {
 		return fmt.Errorf("non-pointer argument")
 	}
-	value := reflect.Indirect(reflect.ValueOf(model))
-	if len(m.fields) == 0 {
-		res, err := buildGraph(value)
+	return m.fill(m.newRand(), reflect.Indirect(reflect.ValueOf(model)))
+}
+
+// Make returns a new filled T. Pointers are allocated and slices get a random
+// length, so T can be a struct, a pointer to one or a slice of either.
+func Make[T any](m *Maker) (T, error) {
+	var res T
+	err := m.fill(m.newRand(), reflect.ValueOf(&res).Elem())
+	return res, err
+}
+
+// MakeN returns n filled values of T drawn from a single random stream.
+func MakeN[T any](m *Maker, n int) ([]T, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("negative count %d", n)
+	}
+	r := m.newRand()
+	res := make([]T, n)
+	for i := range res {
+		if err := m.fill(r, reflect.ValueOf(&res[i]).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (m *Maker) newRand() *rand.Rand {
+	n := m.calls.Add(1) - 1
+	return rand.New(rand.NewSource(int64(mix(uint64(m.seed) + n*0x9e3779b97f4a7c15))))
+}
+
+// mix is the splitmix64 finalizer, spreading nearby seeds far apart.
+func mix(x uint64) uint64 {
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func (m *Maker) fill(r *rand.Rand, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return m.fill(r, value.Elem())
+	case reflect.Slice:
+		if value.Len() == 0 {
+			if err := m.length.Validate(); err != nil {
+				return err
+			}
+			n := m.length.pick(r)
+			value.Set(reflect.MakeSlice(value.Type(), n, n))
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := m.fill(r, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		p, err := m.planFor(value.Type())
 		if err != nil {
 			return err
 		}
-		m.fields = res
+		return m.fillStruct(r, nil, value, p)
+	default:
+		return fmt.Errorf("kind not supported: %s", value.Kind().String())
 	}
-	return m.fillStruct(rand.New(rand.NewSource(m.seed)), value, m.fields)
 }
 
-func buildGraph(valueOf reflect.Value) (map[string]any, error) {
+func buildGraph(typeOf reflect.Type) (map[string]any, error) {
+	return buildStructGraph(typeOf, map[reflect.Type]bool{})
+}
+
+// buildStructGraph maps tagged fields to their tags. Fields holding structs,
+// directly or through pointers, slices, arrays and tagged maps, map to the
+// struct's own graph with the field tag kept under the empty key. Untagged
+// fields whose struct has nothing to fill, such as *time.Time, are skipped so
+// they stay nil, as are fields that would recurse into a struct already being
+// built.
+func buildStructGraph(typeOf reflect.Type, seen map[reflect.Type]bool) (map[string]any, error) {
+	seen[typeOf] = true
+	defer delete(seen, typeOf)
 	graph := make(map[string]any)
-	var err error
-	typeOf := valueOf.Type()
 	for i := 0; i < typeOf.NumField(); i++ {
 		field := typeOf.Field(i)
 		if !field.IsExported() {
 			continue
 		}
 		tagValue := field.Tag.Get(tag)
-		kind := field.Type.Kind()
-		if kind == reflect.Struct {
-			m, _ := buildGraph(valueOf.FieldByName(field.Name))
-			graph[field.Name] = m
-		} else if kind == reflect.Slice {
-			if valueOf.Field(i).Type().Elem().Kind() == reflect.Struct {
-				m, _ := buildGraph(valueOf.Field(i).Index(0))
-				graph[field.Name] = m
-			} else {
-				if tagValue == "" {
-					continue
-				}
-				graph[field.Name] = tagValue
+		spec, err := parseTag(tagValue)
+		if err != nil {
+			return nil, err
+		}
+		inner := field.Type
+		for inner.Kind() == reflect.Pointer || inner.Kind() == reflect.Slice || inner.Kind() == reflect.Array ||
+			inner.Kind() == reflect.Map && tagValue != "" {
+			inner = inner.Elem()
+		}
+		if inner.Kind() == reflect.Struct && spec.gen == "" {
+			if seen[inner] {
+				continue
 			}
-
-		} else {
-			if tagValue == "" {
+			m, err := buildStructGraph(inner, seen)
+			if err != nil {
+				return nil, err
+			}
+			if tagValue != "" {
+				m[""] = tagValue
+			} else if len(m) == 0 {
 				continue
 			}
+			graph[field.Name] = m
+		} else if tagValue != "" {
 			graph[field.Name] = tagValue
 		}
 	}
-	return graph, err
+	return graph, nil
 }
 
-func (m *Maker) fillStruct(r *rand.Rand, valueOf reflect.Value, fields map[string]any) error {
-	var err error
-	for key, val := range fields {
-		fieldsKey := reflect.TypeOf(val).Kind()
-		if fieldsKey == reflect.String {
-			tagValue := val.(string)
-			if valueOf.Kind() == reflect.Slice {
-				return m.fillSlice(r, tagValue, valueOf, fields)
-			}
-			fieldByName := valueOf.FieldByName(key)
-			kind := fieldByName.Kind()
-			if kind == reflect.Struct {
-				err = m.fillStruct(r, fieldByName, fields)
-			} else if kind == reflect.Slice {
-				err = m.fillSlice(r, tagValue, fieldByName, fields)
-			} else {
-				err = m.fillSimple(r, tagValue, fieldByName)
-			}
-		} else if fieldsKey == reflect.Map {
-			err = m.fillStruct(r, valueOf.FieldByName(key), fields[key].(map[string]any))
-		} else {
-			return fmt.Errorf("unrecognized type %v", fieldsKey)
-		}
-		if err != nil {
-			return err
+func (m *Maker) fillStruct(r *rand.Rand, parent *scope, valueOf reflect.Value, p *plan) error {
+	sc := &scope{value: valueOf, parent: parent}
+	for _, f := range p.fields {
+		if err := f.fill(r, sc, valueOf.FieldByIndex(f.index)); err != nil {
+			return fieldErr(f.name, err)
 		}
 	}
 	return nil
 }
 
-func (m *Maker) fillSimple(r *rand.Rand, tagValue string, field reflect.Value) error {
-	switch optionValueOf(tagValue) {
-	case random:
-		if err := fillRandomSimple(r, field, tagValue); err != nil {
-			return err
-		}
-	case regex:
-		if err := fillRegexSimple(r, field, tagValue); err != nil {
-			return err
-		}
-	case fc:
-		if err := fillFuncSimple(m.funcMap, field, tagValue); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("option not available %s", tagValue)
-	}
-	return nil
+// fieldError names the field, as a dot separated path from the struct given
+// to Fill, whose tag failed to compile or fill.
+type fieldError struct {
+	field string
+	err   error
 }
 
-func (m *Maker) fillSlice(r *rand.Rand, tagValue string, field reflect.Value, fields map[string]any) error {
-	for i := 0; i < field.Len(); i++ {
-		index := field.Index(i)
-		var err error
-		if index.Kind() == reflect.Struct {
-			err = m.fillStruct(r, index, fields)
-		} else {
-			err = m.fillSimple(r, tagValue, index)
-		}
-		if err != nil {
-			return err
-		}
+func (e *fieldError) Error() string {
+	return fmt.Sprintf("field %s: %v", e.field, e.err)
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
 
+// fieldErr wraps err with the field name, prepending it to the path of an
+// error from a nested struct.
+func fieldErr(name string, err error) error {
+	if fe, ok := err.(*fieldError); ok {
+		return &fieldError{field: name + "." + fe.field, err: fe.err}
 	}
-	return nil
+	return &fieldError{field: name, err: err}
 }
 
 func optionValueOf(in string) option {
@@ -178,5 +386,28 @@ func optionValueOf(in string) option {
 	if strings.HasPrefix(in, string(fc)) {
 		return fc
 	}
+	if strings.HasPrefix(in, string(rel)) {
+		return rel
+	}
+	if strings.HasPrefix(in, string(hexa)) {
+		return hexa
+	}
+	if strings.HasPrefix(in, string(base64)) {
+		return base64
+	}
+	if strings.HasPrefix(in, string(tm)) {
+		return tm
+	}
+	if strings.HasPrefix(in, string(dur)) {
+		return dur
+	}
+	if strings.HasPrefix(in, string(fake)) {
+		return fake
+	}
+	for _, o := range []option{uuid, ulid, ipa, macAddr, host, uri, oneof} {
+		if strings.HasPrefix(in, string(o)) {
+			return o
+		}
+	}
 	return ""
 }
@@ -1,11 +1,22 @@
 package gomaker_test
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"gomaker"
+	"math"
 	"math/rand"
+	"net"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestMaker_random_with_tags(t *testing.T) {
@@ -43,7 +54,7 @@ func TestMaker_random_with_tags(t *testing.T) {
 			"pass unknown",
 			&unknown{},
 			gomaker.New(),
-			fmt.Errorf("option not available test123"),
+			fmt.Errorf("field UnknownId: option not available test123"),
 			nil,
 		},
 		{
@@ -120,7 +131,7 @@ func TestMaker_random(t *testing.T) {
 			"pass unknown",
 			&unknown{},
 			gomaker.New(gomaker.WithFieldsMapping(map[string]any{"UnknownId": "test123"})),
-			fmt.Errorf("option not available test123"),
+			fmt.Errorf("field UnknownId: option not available test123"),
 			nil,
 		},
 		{
@@ -228,6 +239,21 @@ func BenchmarkRegexFill(b *testing.B) {
 	}
 }
 
+func BenchmarkRegexFill_Email(b *testing.B) {
+	type dummy struct {
+		Email string `gomaker:"regex[[a-z0-9._%+-]{1,20}@[a-z0-9-]{1,12}\\.(com|org|net|io)]"`
+	}
+
+	maker := gomaker.New()
+	model := &dummy{}
+	for i := 0; i < b.N; i++ {
+		err := maker.Fill(model)
+		if err != nil {
+			b.FailNow()
+		}
+	}
+}
+
 func BenchmarkFuncFill(b *testing.B) {
 	type dummy struct {
 		DummyId     int64  `gomaker:"func[randomInt]"`
@@ -321,7 +347,7 @@ func TestMaker_regex(t *testing.T) {
 		{
 			"happy path",
 			&dummy{},
-			gomaker.New(gomaker.WithFieldsMapping(map[string]any{"DummyString": `regex[^\\d+$]`, "DummyInt": `regex[[0-9]{10}]`})),
+			gomaker.New(gomaker.WithFieldsMapping(map[string]any{"DummyString": `regex[^\\d+$]`, "DummyInt": `regex[[1-9][0-9]{8}]`})),
 			nil,
 			func(in *dummy) error {
 				if in.DummyString == "" {
@@ -337,7 +363,7 @@ func TestMaker_regex(t *testing.T) {
 			"fail regex",
 			&failRegex{},
 			gomaker.New(gomaker.WithFieldsMapping(map[string]any{"Str": `regexalmost[]`})),
-			errors.New("regex validation failed"),
+			errors.New("field Str: regex validation failed"),
 			nil,
 		},
 	}
@@ -398,7 +424,7 @@ func TestMaker_func(t *testing.T) {
 			"missing func",
 			&unknown{},
 			gomaker.New(gomaker.WithFuncMap(funcMap)),
-			errors.New("map missing fn missing"),
+			errors.New("field DummyId: map missing fn missing"),
 			nil,
 		},
 		{
@@ -522,35 +548,35 @@ func TestMaker_errors(t *testing.T) {
 			"int64",
 			&dummy{},
 			gomaker.New(gomaker.WithFuncMap(funcMAp), gomaker.WithFieldsMapping(map[string]any{"DummyId": "func[bool]"})),
-			errors.New("expected int64 got bool"),
+			errors.New("field DummyId: fn bool: cannot assign bool to int64"),
 			nil,
 		},
 		{
 			"uint64",
 			&dummy{},
 			gomaker.New(gomaker.WithFuncMap(funcMAp), gomaker.WithFieldsMapping(map[string]any{"DummyUint": "func[bool]"})),
-			errors.New("expected uint64 got bool"),
+			errors.New("field DummyUint: fn bool: cannot assign bool to uint64"),
 			nil,
 		},
 		{
 			"float64",
 			&dummy{},
 			gomaker.New(gomaker.WithFuncMap(funcMAp), gomaker.WithFieldsMapping(map[string]any{"DummyFloat": "func[bool]"})),
-			errors.New("expected float64 got bool"),
+			errors.New("field DummyFloat: fn bool: cannot assign bool to float32"),
 			nil,
 		},
 		{
 			"complex128",
 			&dummy{},
 			gomaker.New(gomaker.WithFuncMap(funcMAp), gomaker.WithFieldsMapping(map[string]any{"DummyComplex": "func[bool]"})),
-			errors.New("expected complex128 got bool"),
+			errors.New("field DummyComplex: fn bool: cannot assign bool to complex128"),
 			nil,
 		},
 		{
 			"string",
 			&dummy{},
 			gomaker.New(gomaker.WithFuncMap(funcMAp), gomaker.WithFieldsMapping(map[string]any{"DummyString": "func[bool]"})),
-			errors.New("expected string got bool"),
+			errors.New("field DummyString: fn bool: cannot assign bool to string"),
 			nil,
 		},
 	}
@@ -628,3 +654,1645 @@ func TestMaker_slices(t *testing.T) {
 		})
 	}
 }
+
+func TestMaker_rel(t *testing.T) {
+	t.Parallel()
+	type item struct {
+		Price   float64 `gomaker:"rand[1;100;1]"`
+		OrderId int64   `gomaker:"rel[copy;^.Id]"`
+	}
+	type dummy struct {
+		Id        int64   `gomaker:"rand[1;1000;1]"`
+		FirstName string  `gomaker:"rand[5;5;]"`
+		Email     string  `gomaker:"rel[fmt;{FirstName}.{Id}@example.com]"`
+		Start     int64   `gomaker:"rand[1;100;1]"`
+		End       int64   `gomaker:"rel[offset;Start;1;10]"`
+		Total     float64 `gomaker:"rel[sum;Items.Price]"`
+		Items     []item
+	}
+	type cycle struct {
+		A int64 `gomaker:"rel[copy;B]"`
+		B int64 `gomaker:"rel[copy;A]"`
+	}
+	type missing struct {
+		A int64 `gomaker:"rel[copy;Missing]"`
+	}
+	tests := []struct {
+		name   string
+		arg    any
+		err    error
+		sanity func(in *dummy) error
+	}{
+		{
+			"happy path",
+			&dummy{Items: make([]item, 3)},
+			nil,
+			func(in *dummy) error {
+				if in.Email != fmt.Sprintf("%s.%d@example.com", in.FirstName, in.Id) {
+					return fmt.Errorf("email not built %s", in.Email)
+				}
+				if in.End <= in.Start || in.End > in.Start+10 {
+					return fmt.Errorf("end %d not after start %d", in.End, in.Start)
+				}
+				var total float64
+				for _, it := range in.Items {
+					if it.OrderId != in.Id {
+						return errors.New("item order id not copied")
+					}
+					total += it.Price
+				}
+				if in.Total != total {
+					return fmt.Errorf("total %v expected %v", in.Total, total)
+				}
+				return nil
+			},
+		},
+		{
+			"cycle",
+			&cycle{},
+			errors.New("rel cycle detected: A -> B -> A"),
+			nil,
+		},
+		{
+			"missing field",
+			&missing{},
+			errors.New("field A: rel field not found Missing"),
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gomaker.New().Fill(tt.arg)
+			if err != nil {
+				if (tt.err != nil && err.Error() != tt.err.Error()) || tt.err == nil {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Fatalf("expected: %v, got: nil", tt.err)
+			}
+			if tt.sanity != nil {
+				err = tt.sanity(tt.arg.(*dummy))
+				if err != nil {
+					t.Fatalf("sanity check failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaker_relArithmetic(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Big        int64      `gomaker:"rand[9007199254740993;9007199254740994;1]"`
+		Sum        int64      `gomaker:"rel[sum;Big]"`
+		StartNanos int64      `gomaker:"rand[1700000000000000001;1700000000000000002;1]"`
+		EndNanos   int64      `gomaker:"rel[offset;StartNanos;1;30]"`
+		Huge       []uint64   `gomaker:"rand[18446744073709551613;18446744073709551614;1] len[1;1]"`
+		HugeSum    uint64     `gomaker:"rel[sum;Huge]"`
+		Start      time.Time  `gomaker:"time[2024-01-01T00:00:00Z;2024-12-31T00:00:00Z]"`
+		End        time.Time  `gomaker:"rel[offset;Start;1h;30d]"`
+		EndPtr     *time.Time `gomaker:"rel[offset;Start;-2h;-1h] nil[0]"`
+	}
+	ds, err := gomaker.MakeN[dummy](gomaker.New(gomaker.WithSeed(12)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		if d.Sum != 9007199254740993 || d.HugeSum != 18446744073709551613 {
+			t.Fatalf("sum lost precision %d %d", d.Sum, d.HugeSum)
+		}
+		if diff := d.EndNanos - d.StartNanos; diff < 1 || diff >= 30 {
+			t.Fatalf("offset %d not in [1, 30)", diff)
+		}
+		if diff := d.End.Sub(d.Start); diff < time.Hour || diff >= 30*24*time.Hour {
+			t.Fatalf("end %v not 1h to 30d after start %v", d.End, d.Start)
+		}
+		if diff := d.Start.Sub(*d.EndPtr); diff <= time.Hour || diff > 2*time.Hour {
+			t.Fatalf("end %v not 1h to 2h before start %v", *d.EndPtr, d.Start)
+		}
+	}
+
+	type negative struct {
+		Values []int64 `gomaker:"rand[-5;-4;1] len[2;2]"`
+		Total  uint8   `gomaker:"rel[sum;Values]"`
+	}
+	if err = gomaker.New().Fill(&negative{}); err == nil || err.Error() != "field Total: rel value -10 overflows uint8" {
+		t.Errorf("expected: field Total: rel value -10 overflows uint8, got: %v", err)
+	}
+	type overflow struct {
+		Values []int64 `gomaker:"rand[9223372036854775805;9223372036854775806;1] len[2;2]"`
+		Total  int64   `gomaker:"rel[sum;Values]"`
+	}
+	if err = gomaker.New().Fill(&overflow{}); err == nil || err.Error() != "field Total: rel value 18446744073709551610 overflows int64" {
+		t.Errorf("expected: field Total: rel value 18446744073709551610 overflows int64, got: %v", err)
+	}
+	type notTime struct {
+		Start int64     `gomaker:"rand"`
+		End   time.Time `gomaker:"rel[offset;Start;1h;2h]"`
+	}
+	if err = gomaker.New().Fill(&notTime{}); err == nil || err.Error() != "field End: rel offset on non time kind: int64" {
+		t.Errorf("expected: field End: rel offset on non time kind: int64, got: %v", err)
+	}
+
+}
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		DummyId     int64  `gomaker:"rand[1;10;1]"`
+		DummyString string `gomaker:"rand"`
+	}
+	check := func(in dummy) error {
+		if in.DummyId == 0 {
+			return errors.New("int not assigned")
+		}
+		if in.DummyString == "" {
+			return errors.New("string not assigned")
+		}
+		return nil
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		d, err := gomaker.Make[dummy](gomaker.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = check(d); err != nil {
+			t.Fatalf("sanity check failed: %v", err)
+		}
+	})
+	t.Run("pointer", func(t *testing.T) {
+		d, err := gomaker.Make[*dummy](gomaker.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d == nil {
+			t.Fatal("pointer not allocated")
+		}
+		if err = check(*d); err != nil {
+			t.Fatalf("sanity check failed: %v", err)
+		}
+	})
+	t.Run("slice", func(t *testing.T) {
+		ds, err := gomaker.Make[[]dummy](gomaker.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ds) == 0 {
+			t.Fatal("slice not allocated")
+		}
+		for _, d := range ds {
+			if err = check(d); err != nil {
+				t.Fatalf("sanity check failed: %v", err)
+			}
+		}
+	})
+	t.Run("not supported", func(t *testing.T) {
+		_, err := gomaker.Make[int](gomaker.New())
+		if err == nil || err.Error() != "kind not supported: int" {
+			t.Fatalf("expected: kind not supported: int, got: %v", err)
+		}
+	})
+}
+
+func TestMakeN(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		DummyId     int64  `gomaker:"rand[1;1000000;1]"`
+		DummyString string `gomaker:"rand[10;10;]"`
+	}
+	ds, err := gomaker.MakeN[*dummy](gomaker.New(gomaker.WithSeed(123)), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 5 {
+		t.Fatalf("got %d values expected 5", len(ds))
+	}
+	seen := map[string]bool{}
+	for _, d := range ds {
+		if d.DummyString == "" {
+			t.Fatal("string not assigned")
+		}
+		seen[d.DummyString] = true
+	}
+	if len(seen) != len(ds) {
+		t.Errorf("expected distinct values got %v", seen)
+	}
+	if _, err = gomaker.MakeN[dummy](gomaker.New(), -1); err == nil {
+		t.Error("expected error for negative count")
+	}
+}
+
+func TestMaker_multipleTypes(t *testing.T) {
+	t.Parallel()
+	type first struct {
+		Id   int64  `gomaker:"rand[1;10;1]"`
+		Name string `gomaker:"regex[[a-z]{5}]"`
+	}
+	type second struct {
+		Code  string  `gomaker:"rand[3;3;]"`
+		Score float64 `gomaker:"rand[1;100;1]"`
+	}
+	maker := gomaker.New()
+	for i := 0; i < 3; i++ {
+		f := &first{}
+		if err := maker.Fill(f); err != nil {
+			t.Fatal(err)
+		}
+		if f.Id == 0 || len(f.Name) != 5 {
+			t.Fatalf("first not assigned %+v", f)
+		}
+		s := &second{}
+		if err := maker.Fill(s); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Code) != 3 || s.Score == 0 {
+			t.Fatalf("second not assigned %+v", s)
+		}
+	}
+}
+
+func TestMaker_concurrent(t *testing.T) {
+	t.Parallel()
+	type inner struct {
+		Value float64 `gomaker:"rand[1;100;1]"`
+	}
+	type dummy struct {
+		Id     int64  `gomaker:"rand[1;1000000;1]"`
+		Name   string `gomaker:"regex[[a-z]{8}]"`
+		Label  string `gomaker:"func[label]"`
+		Copy   int64  `gomaker:"rel[copy;Id]"`
+		Inners []inner
+	}
+	type other struct {
+		Code string `gomaker:"rand[6;6;]"`
+	}
+	funcMap := map[string]func() any{"label": func() any {
+		return "label"
+	}}
+	const workers, calls = 16, 50
+	expected := gomaker.New(gomaker.WithSeed(42), gomaker.WithFuncMap(funcMap))
+	want := map[string]int{}
+	for i := 0; i < workers*calls; i++ {
+		d, err := gomaker.Make[dummy](expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[fmt.Sprintf("%+v", d)]++
+	}
+
+	maker := gomaker.New(gomaker.WithSeed(42), gomaker.WithFuncMap(funcMap))
+	forked := maker.Fork("other")
+	var mu sync.Mutex
+	got := map[string]int{}
+	t.Run("workers", func(t *testing.T) {
+		for i := 0; i < workers; i++ {
+			t.Run(fmt.Sprintf("worker %d", i), func(t *testing.T) {
+				t.Parallel()
+				for j := 0; j < calls; j++ {
+					d, err := gomaker.Make[dummy](maker)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if d.Label != "label" || d.Copy != d.Id {
+						t.Errorf("not assigned %+v", d)
+						return
+					}
+					mu.Lock()
+					got[fmt.Sprintf("%+v", d)]++
+					mu.Unlock()
+					if err = forked.Fill(&other{}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			})
+		}
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parallel output differs from sequential output")
+	}
+}
+
+func TestMaker_streams(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Id   int64  `gomaker:"rand[1;1000000;1]"`
+		Name string `gomaker:"rand[10;10;]"`
+	}
+	maker := gomaker.New(gomaker.WithSeed(7))
+	first, err := gomaker.Make[dummy](maker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := gomaker.Make[dummy](maker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("repeated calls produced the same value %+v", first)
+	}
+
+	maker.Reset()
+	again := dummy{}
+	if err = maker.Fill(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Errorf("after reset got %+v expected %+v", again, first)
+	}
+
+	fork1, err := gomaker.Make[dummy](maker.Fork("orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fork2, err := gomaker.Make[dummy](gomaker.New(gomaker.WithSeed(7)).Fork("orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork1 != fork2 {
+		t.Errorf("forks with the same name differ %+v %+v", fork1, fork2)
+	}
+	if fork1 == first || fork1 == second {
+		t.Errorf("fork repeats the parent stream %+v", fork1)
+	}
+	other, err := gomaker.Make[dummy](maker.Fork("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == fork1 {
+		t.Errorf("forks with different names produced the same value %+v", other)
+	}
+}
+
+func TestMaker_sliceLen(t *testing.T) {
+	t.Parallel()
+	type inner struct {
+		Floats float64 `gomaker:"rand[1;100;0.1]"`
+	}
+	type dummy struct {
+		Ints    []int64  `gomaker:"rand[1;10;1] len[2;4]"`
+		Strs    []string `gomaker:"regex[[a-z ]{5}] len[3;3]"`
+		Inners  []inner  `gomaker:"len[1;2]"`
+		Default []inner
+	}
+	type badLen struct {
+		Ints []int64 `gomaker:"rand len[5;1]"`
+	}
+	tests := []struct {
+		name   string
+		arg    any
+		maker  *gomaker.Maker
+		err    error
+		sanity func(in *dummy) error
+	}{
+		{
+			"allocated",
+			&dummy{},
+			gomaker.New(gomaker.WithSliceLen(5, 5)),
+			nil,
+			func(in *dummy) error {
+				if len(in.Ints) < 2 || len(in.Ints) > 4 || in.Ints[0] == 0 {
+					return fmt.Errorf("ints not assigned %v", in.Ints)
+				}
+				if len(in.Strs) != 3 || len(in.Strs[0]) != 5 {
+					return fmt.Errorf("strs not assigned %v", in.Strs)
+				}
+				if len(in.Inners) < 1 || len(in.Inners) > 2 || in.Inners[0].Floats == 0 {
+					return fmt.Errorf("inners not assigned %v", in.Inners)
+				}
+				if len(in.Default) != 5 || in.Default[4].Floats == 0 {
+					return fmt.Errorf("default not assigned %v", in.Default)
+				}
+				return nil
+			},
+		},
+		{
+			"preallocated",
+			&dummy{Ints: make([]int64, 7)},
+			gomaker.New(),
+			nil,
+			func(in *dummy) error {
+				if len(in.Ints) != 7 || in.Ints[6] == 0 {
+					return fmt.Errorf("ints not assigned %v", in.Ints)
+				}
+				return nil
+			},
+		},
+		{
+			"bad len",
+			&badLen{},
+			gomaker.New(),
+			errors.New("field Ints: len min bigger then max"),
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.maker.Fill(tt.arg)
+			if err != nil {
+				if (tt.err != nil && err.Error() != tt.err.Error()) || tt.err == nil {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+			}
+			if tt.sanity != nil {
+				err = tt.sanity(tt.arg.(*dummy))
+				if err != nil {
+					t.Fatalf("sanity check failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaker_pointers(t *testing.T) {
+	t.Parallel()
+	type address struct {
+		Street string `gomaker:"rand[5;5;]"`
+		Number *int32 `gomaker:"rand[1;100;1]"`
+	}
+	type node struct {
+		Value int64 `gomaker:"rand[1;10;1]"`
+		Next  *node
+	}
+	type dummy struct {
+		Name      *string `gomaker:"regex[[a-z]{4}]"`
+		Never     *string `gomaker:"rand nil[1]"`
+		Address   *address
+		Addresses []*address `gomaker:"len[3;3] nil[0]"`
+		Node      node
+		Copy      *int32 `gomaker:"rel[copy;Address.Number]"`
+	}
+	type badChance struct {
+		Name *string `gomaker:"rand nil[2]"`
+	}
+	tests := []struct {
+		name   string
+		arg    any
+		maker  *gomaker.Maker
+		err    error
+		sanity func(in *dummy) error
+	}{
+		{
+			"allocated",
+			&dummy{},
+			gomaker.New(),
+			nil,
+			func(in *dummy) error {
+				if in.Name == nil || len(*in.Name) != 4 {
+					return errors.New("name not assigned")
+				}
+				if in.Never != nil {
+					return errors.New("never assigned")
+				}
+				if in.Address == nil || in.Address.Street == "" || in.Address.Number == nil || *in.Address.Number == 0 {
+					return errors.New("address not assigned")
+				}
+				if len(in.Addresses) != 3 || in.Addresses[2] == nil || in.Addresses[2].Street == "" {
+					return errors.New("addresses not assigned")
+				}
+				if in.Node.Value == 0 || in.Node.Next != nil {
+					return errors.New("node not assigned")
+				}
+				if in.Copy == nil || *in.Copy != *in.Address.Number {
+					return errors.New("copy not assigned")
+				}
+				return nil
+			},
+		},
+		{
+			"always nil",
+			&dummy{},
+			gomaker.New(gomaker.WithNilChance(1)),
+			nil,
+			func(in *dummy) error {
+				if in.Name != nil || in.Address != nil || in.Copy != nil {
+					return errors.New("pointer assigned")
+				}
+				if len(in.Addresses) != 3 || in.Addresses[0] == nil {
+					return errors.New("nil modifier not applied")
+				}
+				return nil
+			},
+		},
+		{
+			"bad chance",
+			&badChance{},
+			gomaker.New(),
+			errors.New("field Name: nil chance 2 not between 0 and 1"),
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.maker.Fill(tt.arg)
+			if err != nil {
+				if (tt.err != nil && err.Error() != tt.err.Error()) || tt.err == nil {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Fatalf("expected: %v, got: nil", tt.err)
+			}
+			if tt.sanity != nil {
+				err = tt.sanity(tt.arg.(*dummy))
+				if err != nil {
+					t.Fatalf("sanity check failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaker_nilChance(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Name *string `gomaker:"rand nil[0.3]"`
+	}
+	maker := gomaker.New(gomaker.WithSeed(1))
+	nils := 0
+	const n = 2000
+	for i := 0; i < n; i++ {
+		d, err := gomaker.Make[dummy](maker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Name == nil {
+			nils++
+		}
+	}
+	if ratio := float64(nils) / n; ratio < 0.25 || ratio > 0.35 {
+		t.Errorf("nil ratio %v expected around 0.3", ratio)
+	}
+}
+
+func TestMaker_untaggedStructs(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Name      string `gomaker:"rand[5;5;]"`
+		DeletedAt *time.Time
+		Seen      []time.Time
+		Addr      *netip.Addr
+		Times     []*time.Time
+	}
+	d, err := gomaker.Make[dummy](gomaker.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name == "" {
+		t.Error("name not assigned")
+	}
+	if d.DeletedAt != nil || d.Seen != nil || d.Addr != nil || d.Times != nil {
+		t.Errorf("untagged fields assigned: %+v", d)
+	}
+}
+
+func TestMaker_maps(t *testing.T) {
+	t.Parallel()
+	type item struct {
+		Price float64 `gomaker:"rand[1;100;1]"`
+	}
+	type dummy struct {
+		Scores  map[string]int64 `gomaker:"map[len=1;5] key=regex[[a-z]{4}] val=rand[1;100;1]"`
+		Items   map[int]item     `gomaker:"map[len=3;3] key=rand[1;1000;1]"`
+		Refs    map[string]*item `gomaker:"map[len=2;2] key=rand[8;8;] nil[0]"`
+		Skipped map[string]item
+	}
+	type duplicates struct {
+		Flags map[bool]int64 `gomaker:"map[len=3;3] key=rand val=rand"`
+	}
+	type missingKey struct {
+		Flags map[string]int64 `gomaker:"map[len=3;3] val=rand"`
+	}
+	type missingVal struct {
+		Flags map[string]int64 `gomaker:"map[len=3;3] key=rand"`
+	}
+	tests := []struct {
+		name   string
+		arg    any
+		err    error
+		sanity func(in *dummy) error
+	}{
+		{
+			"happy path",
+			&dummy{},
+			nil,
+			func(in *dummy) error {
+				if len(in.Scores) < 1 || len(in.Scores) > 5 {
+					return fmt.Errorf("scores not assigned %v", in.Scores)
+				}
+				for k, v := range in.Scores {
+					if len(k) != 4 || v == 0 {
+						return fmt.Errorf("scores not assigned %v", in.Scores)
+					}
+				}
+				if len(in.Items) != 3 {
+					return fmt.Errorf("items not assigned %v", in.Items)
+				}
+				for _, v := range in.Items {
+					if v.Price == 0 {
+						return fmt.Errorf("items not assigned %v", in.Items)
+					}
+				}
+				if len(in.Refs) != 2 {
+					return fmt.Errorf("refs not assigned %v", in.Refs)
+				}
+				for _, v := range in.Refs {
+					if v == nil || v.Price == 0 {
+						return fmt.Errorf("refs not assigned %v", in.Refs)
+					}
+				}
+				if in.Skipped != nil {
+					return errors.New("untagged map assigned")
+				}
+				return nil
+			},
+		},
+		{
+			"duplicate keys",
+			&duplicates{},
+			errors.New("field Flags: map got 2 unique keys expected at least 3"),
+			nil,
+		},
+		{
+			"missing key",
+			&missingKey{},
+			errors.New("field Flags: map key generator missing"),
+			nil,
+		},
+		{
+			"missing val",
+			&missingVal{},
+			errors.New("field Flags: map val generator missing"),
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gomaker.New().Fill(tt.arg)
+			if err != nil {
+				if (tt.err != nil && err.Error() != tt.err.Error()) || tt.err == nil {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Fatalf("expected: %v, got: nil", tt.err)
+			}
+			if tt.sanity != nil {
+				err = tt.sanity(tt.arg.(*dummy))
+				if err != nil {
+					t.Fatalf("sanity check failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaker_arrays(t *testing.T) {
+	t.Parallel()
+	type item struct {
+		Price float64 `gomaker:"rand[1;100;1]"`
+	}
+	type dummy struct {
+		Id      [16]byte   `gomaker:"hex"`
+		Fixed   [2]byte    `gomaker:"hex[00ff]"`
+		Encoded [3]byte    `gomaker:"base64[AQID]"`
+		Vector  [3]float64 `gomaker:"rand[1;10;0.5]"`
+		Words   [2]string  `gomaker:"regex[[a-z]{3}]"`
+		Items   [2]item
+		Grid    [2][2]int8 `gomaker:"rand[1;9;1]"`
+	}
+	type badSize struct {
+		Id [4]byte `gomaker:"hex[00ff]"`
+	}
+	type badKind struct {
+		Id [4]int32 `gomaker:"hex"`
+	}
+	tests := []struct {
+		name   string
+		arg    any
+		err    error
+		sanity func(in *dummy) error
+	}{
+		{
+			"happy path",
+			&dummy{},
+			nil,
+			func(in *dummy) error {
+				if in.Id == [16]byte{} {
+					return errors.New("id not assigned")
+				}
+				if in.Fixed != [2]byte{0, 255} {
+					return fmt.Errorf("fixed not assigned %v", in.Fixed)
+				}
+				if in.Encoded != [3]byte{1, 2, 3} {
+					return fmt.Errorf("encoded not assigned %v", in.Encoded)
+				}
+				for _, v := range in.Vector {
+					if v == 0 {
+						return fmt.Errorf("vector not assigned %v", in.Vector)
+					}
+				}
+				if len(in.Words[1]) != 3 {
+					return fmt.Errorf("words not assigned %v", in.Words)
+				}
+				if in.Items[1].Price == 0 {
+					return fmt.Errorf("items not assigned %v", in.Items)
+				}
+				if in.Grid[1][1] == 0 {
+					return fmt.Errorf("grid not assigned %v", in.Grid)
+				}
+				return nil
+			},
+		},
+		{
+			"bad size",
+			&badSize{},
+			errors.New("field Id: hex decoded 2 bytes expected 4"),
+			nil,
+		},
+		{
+			"bad kind",
+			&badKind{},
+			errors.New("field Id: kind not supported: array"),
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gomaker.New().Fill(tt.arg)
+			if err != nil {
+				if (tt.err != nil && err.Error() != tt.err.Error()) || tt.err == nil {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Fatalf("expected: %v, got: nil", tt.err)
+			}
+			if tt.sanity != nil {
+				err = tt.sanity(tt.arg.(*dummy))
+				if err != nil {
+					t.Fatalf("sanity check failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaker_encoded(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Hex      string  `gomaker:"hex"`
+		Base64   string  `gomaker:"base64"`
+		Short    string  `gomaker:"hex[bytes=4]"`
+		Token    string  `gomaker:"base64[bytes=32]"`
+		HexAs    [3]byte `gomaker:"hex[00ff10]"`
+		Base64As [3]byte `gomaker:"base64[00ff]"`
+	}
+	hexText := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	d, err := gomaker.Make[dummy](gomaker.New(gomaker.WithSeed(13)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hexText.MatchString(d.Hex) || len(d.Short) != 8 {
+		t.Errorf("hex not encoded %q %q", d.Hex, d.Short)
+	}
+	for _, s := range []string{d.Base64, d.Token} {
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil || hexText.MatchString(s) {
+			t.Errorf("base64 not encoded %q", s)
+		}
+	}
+	if b, _ := base64.StdEncoding.DecodeString(d.Token); len(b) != 32 {
+		t.Errorf("token has %d bytes expected 32", len(b))
+	}
+	if d.HexAs != [3]byte{0x00, 0xff, 0x10} || d.Base64As != [3]byte{0xd3, 0x47, 0xdf} {
+		t.Errorf("constants not decoded %x %x", d.HexAs, d.Base64As)
+	}
+	type badOption struct {
+		Hex string `gomaker:"hex[len=4]"`
+	}
+	if err = gomaker.New().Fill(&badOption{}); err == nil || err.Error() != "field Hex: hex option not available len=4" {
+		t.Errorf("expected: field Hex: hex option not available len=4, got: %v", err)
+	}
+}
+
+func TestMaker_time(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Created  time.Time     `gomaker:"time[2020-01-01T00:00:00Z;2020-12-31T23:59:59Z]"`
+		Recent   *time.Time    `gomaker:"time[-30d;now;tz=Europe/Belgrade;trunc=1h]"`
+		Day      time.Time     `gomaker:"time[2020-01-01;2020-02-01;trunc=24h]"`
+		Timeout  time.Duration `gomaker:"dur[1s;5m;1s]"`
+		Untagged time.Time
+	}
+	now := time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC)
+	maker := gomaker.New(gomaker.WithNow(now))
+	for i := 0; i < 50; i++ {
+		d, err := gomaker.Make[dummy](maker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Created.Year() != 2020 || d.Created.Location() != time.UTC {
+			t.Fatalf("created not assigned %v", d.Created)
+		}
+		if d.Recent == nil || d.Recent.After(now) || d.Recent.Before(now.AddDate(0, 0, -30)) ||
+			d.Recent.Minute() != 0 || d.Recent.Location().String() != "Europe/Belgrade" {
+			t.Fatalf("recent not assigned %v", d.Recent)
+		}
+		if d.Day.Hour() != 0 || d.Day.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("day not assigned %v", d.Day)
+		}
+		if d.Timeout < time.Second || d.Timeout > 5*time.Minute || d.Timeout%time.Second != 0 {
+			t.Fatalf("timeout not assigned %v", d.Timeout)
+		}
+		if !d.Untagged.IsZero() {
+			t.Fatalf("untagged assigned %v", d.Untagged)
+		}
+	}
+
+	type badKind struct {
+		Created int64 `gomaker:"time"`
+	}
+	if err := maker.Fill(&badKind{}); err == nil || err.Error() != "field Created: kind not supported: int64" {
+		t.Errorf("expected: field Created: kind not supported: int64, got: %v", err)
+	}
+}
+
+func TestMaker_randomBounds(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Signed   int64   `gomaker:"rand[-50;50;1]"`
+		Negative int32   `gomaker:"rand[-10;-5;1]"`
+		Float    float64 `gomaker:"rand[-1.5;2.5;0.25]"`
+		Huge     uint64  `gomaker:"rand[18446744073709551000;18446744073709551615;1]"`
+		Wide     int64   `gomaker:"rand[-9223372036854775808;9223372036854775807;1]"`
+	}
+	maker := gomaker.New()
+	for i := 0; i < 100; i++ {
+		d, err := gomaker.Make[dummy](maker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Signed < -50 || d.Signed >= 50 {
+			t.Fatalf("signed out of range %v", d.Signed)
+		}
+		if d.Negative < -10 || d.Negative >= -5 {
+			t.Fatalf("negative out of range %v", d.Negative)
+		}
+		if d.Float < -1.5 || d.Float >= 2.5 || math.Mod(d.Float, 0.25) != 0 {
+			t.Fatalf("float out of range %v", d.Float)
+		}
+		if d.Huge < 18446744073709551000 {
+			t.Fatalf("huge out of range %v", d.Huge)
+		}
+	}
+
+	type bad struct {
+		Id int64 `gomaker:"rand[a;1;1]"`
+	}
+	err := maker.Fill(&bad{})
+	if err == nil || err.Error() != `field Id: rand min: strconv.ParseInt: parsing "a": invalid syntax` {
+		t.Errorf("expected parse error got %v", err)
+	}
+}
+
+func TestMaker_overflow(t *testing.T) {
+	t.Parallel()
+	type inner struct {
+		Small int8 `gomaker:"rand[1;1000;1]"`
+	}
+	type nested struct {
+		Inner inner
+	}
+	type dummy struct {
+		Total  int8    `gomaker:"rel[sum;Values]"`
+		Values []int64 `gomaker:"rand[100;101;1] len[2;2]"`
+	}
+	type float32Overflow struct {
+		Value float32 `gomaker:"rand[1;1e39;1]"`
+	}
+	maker := gomaker.New()
+	if err := maker.Fill(&nested{}); err == nil || err.Error() != "field Inner.Small: rand max 1000 overflows int8" {
+		t.Errorf("expected: field Inner.Small: rand max 1000 overflows int8, got: %v", err)
+	}
+	if err := maker.Fill(&float32Overflow{}); err == nil || err.Error() != "field Value: rand max 1e+39 overflows float32" {
+		t.Errorf("expected: field Value: rand max 1e+39 overflows float32, got: %v", err)
+	}
+	err := maker.Fill(&dummy{})
+	if err == nil || err.Error() != "field Total: rel value 200 overflows int8" {
+		t.Errorf("expected: field Total: rel value 200 overflows int8, got: %v", err)
+	}
+	type clamped struct {
+		Byte  uint8 `gomaker:"rand[200;;]"`
+		Short int16 `gomaker:"rand[;-30000;]"`
+	}
+	for i := 0; i < 100; i++ {
+		d, err := gomaker.Make[clamped](maker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Byte < 200 || d.Short >= -30000 {
+			t.Fatalf("not clamped %+v", d)
+		}
+	}
+}
+
+func TestMaker_distributions(t *testing.T) {
+	t.Parallel()
+	type order struct {
+		Amount  float64 `gomaker:"rand[0;;0.01;dist=lognormal(3,1)]"`
+		Latency int64   `gomaker:"rand[;;;dist=normal(200,20)]"`
+		Rank    uint16  `gomaker:"rand[1;100;1;dist=zipf(1.5,1)]"`
+		Tier    int8    `gomaker:"rand[;;;dist=buckets(1:2:8,2:3:2)]"`
+	}
+	ds, err := gomaker.MakeN[order](gomaker.New(gomaker.WithSeed(3)), 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var latency float64
+	ranks, tiers := map[uint16]int{}, map[int8]int{}
+	for _, d := range ds {
+		if d.Amount < 0 || math.Abs(d.Amount*100-math.Round(d.Amount*100)) > 1e-6 {
+			t.Fatalf("amount not assigned %v", d.Amount)
+		}
+		latency += float64(d.Latency)
+		ranks[d.Rank]++
+		tiers[d.Tier]++
+	}
+	if mean := latency / float64(len(ds)); mean < 195 || mean > 205 {
+		t.Errorf("latency mean %v expected around 200", mean)
+	}
+	if ranks[1] < ranks[2] || ranks[2] < ranks[3] {
+		t.Errorf("ranks not zipf distributed %v", ranks)
+	}
+	if len(tiers) != 2 || tiers[1] < 3*tiers[2] {
+		t.Errorf("tiers not weighted %v", tiers)
+	}
+
+	type fromZero struct {
+		Wait  int64   `gomaker:"rand[;;;dist=exp(1)]"`
+		Delay float64 `gomaker:"rand[;;0.001;dist=exp(0.5)]"`
+		Rank  int32   `gomaker:"rand[;;;dist=zipf(2,1)]"`
+	}
+	zs, err := gomaker.MakeN[fromZero](gomaker.New(gomaker.WithSeed(3)), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delay float64
+	for _, z := range zs {
+		if z.Wait < 0 || z.Wait > 50 || z.Rank < 0 {
+			t.Fatalf("omitted min not 0 %+v", z)
+		}
+		delay += z.Delay
+	}
+	if mean := delay / float64(len(zs)); mean < 1.8 || mean > 2.2 {
+		t.Errorf("delay mean %v expected around 2", mean)
+	}
+
+	type bad struct {
+		Flag bool `gomaker:"rand[;;;dist=normal(1,1)]"`
+	}
+	if err = gomaker.New().Fill(&bad{}); err == nil || err.Error() != "field Flag: dist not supported on kind: bool" {
+		t.Errorf("expected: field Flag: dist not supported on kind: bool, got: %v", err)
+	}
+}
+
+func TestMaker_alphabets(t *testing.T) {
+	t.Parallel()
+	type account struct {
+		ID     string `gomaker:"rand[8;9;1;alphabet=hex;prefix=acc_]"`
+		Pin    string `gomaker:"rand[4;5;1;alphabet=digits]"`
+		Code   string `gomaker:"rand[6;7;1;chars=A-Z2-7]"`
+		Vowels string `gomaker:"rand[5;6;1;alphabet=vowels;suffix=!]"`
+		Name   string `gomaker:"rand[3;4;1;alphabet=greek]"`
+		Slug   string `gomaker:"rand[8;9;1;chars=a-c\\-]"`
+	}
+	m := gomaker.New(gomaker.WithSeed(1), gomaker.WithAlphabet("vowels", "aeiou"), gomaker.WithAlphabet("greek", "α-ω"))
+	only := func(s, chars string) bool {
+		for _, c := range s {
+			if !strings.ContainsRune(chars, c) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := 0; i < 100; i++ {
+		a, err := gomaker.Make[account](m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(a.ID, "acc_") || len(a.ID) != 12 || !only(a.ID[4:], "0123456789abcdef") {
+			t.Fatalf("id not assigned %s", a.ID)
+		}
+		if len(a.Pin) != 4 || !only(a.Pin, "0123456789") {
+			t.Fatalf("pin not assigned %s", a.Pin)
+		}
+		if len(a.Code) != 6 || !only(a.Code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567") {
+			t.Fatalf("code not assigned %s", a.Code)
+		}
+		if !strings.HasSuffix(a.Vowels, "!") || len(a.Vowels) != 6 || !only(a.Vowels[:5], "aeiou") {
+			t.Fatalf("vowels not assigned %s", a.Vowels)
+		}
+		if runes := []rune(a.Name); len(runes) != 3 || !only(a.Name, "αβγδεζηθικλμνξοπρςστυφχψω") {
+			t.Fatalf("name not assigned %s", a.Name)
+		}
+		if len(a.Slug) != 8 || !only(a.Slug, "abc-") {
+			t.Fatalf("slug not assigned %s", a.Slug)
+		}
+	}
+
+	tests := []struct {
+		name string
+		val  any
+		err  string
+	}{
+		{"unknown", &struct {
+			S string `gomaker:"rand[;;;alphabet=klingon]"`
+		}{}, "field S: alphabet not available klingon"},
+		{"exclusive", &struct {
+			S string `gomaker:"rand[;;;alphabet=hex;chars=abc]"`
+		}{}, "field S: rand alphabet and chars are exclusive"},
+		{"not string", &struct {
+			I int `gomaker:"rand[;;;prefix=a]"`
+		}{}, "field I: rand option prefix not supported on kind: int"},
+		{"bad registered", &struct {
+			S string `gomaker:"rand[;;;alphabet=broken]"`
+		}{}, "field S: alphabet broken: rand chars range z-a reversed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gomaker.New(gomaker.WithAlphabet("broken", "z-a")).Fill(tt.val)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected: %s, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMaker_fake(t *testing.T) {
+	t.Parallel()
+	type person struct {
+		FirstName string  `gomaker:"fake[firstname]"`
+		LastName  string  `gomaker:"fake[lastname]"`
+		Name      string  `gomaker:"fake[name]"`
+		Email     string  `gomaker:"fake[email]"`
+		Street    string  `gomaker:"fake[street]"`
+		City      string  `gomaker:"fake[city]"`
+		Zip       string  `gomaker:"fake[zip]"`
+		Phone     *string `gomaker:"fake[phone]"`
+		Company   string  `gomaker:"fake[company]"`
+		Bio       string  `gomaker:"fake[lorem;words=7]"`
+	}
+	email := regexp.MustCompile(`^[a-z]+\.[a-z]+\d*@example\.(com|org|net)$`)
+	phone := regexp.MustCompile(`^[-+() 0-9]+$`)
+	ps, err := gomaker.MakeN[person](gomaker.New(gomaker.WithSeed(5)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range ps {
+		if p.FirstName == "" || p.LastName == "" || len(strings.Fields(p.Name)) < 2 || p.City == "" || p.Company == "" {
+			t.Fatalf("names not assigned %+v", p)
+		}
+		if !email.MatchString(p.Email) {
+			t.Fatalf("email not assigned %s", p.Email)
+		}
+		if number, _, _ := strings.Cut(p.Street, " "); strings.Trim(number, "0123456789") != "" || number == "" {
+			t.Fatalf("street not assigned %s", p.Street)
+		}
+		if strings.Trim(p.Zip, "0123456789-") != "" || !phone.MatchString(*p.Phone) {
+			t.Fatalf("zip or phone not assigned %s %s", p.Zip, *p.Phone)
+		}
+		if len(strings.Fields(p.Bio)) != 7 {
+			t.Fatalf("bio not assigned %s", p.Bio)
+		}
+	}
+	again, err := gomaker.MakeN[person](gomaker.New(gomaker.WithSeed(5)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ps, again) {
+		t.Error("same seed produced different fakes")
+	}
+
+	bad := struct {
+		Age int `gomaker:"fake[firstname]"`
+	}{}
+	if err = gomaker.New().Fill(&bad); err == nil || err.Error() != "field Age: kind not supported: int" {
+		t.Errorf("expected: field Age: kind not supported: int, got: %v", err)
+	}
+}
+
+func TestMaker_locales(t *testing.T) {
+	t.Parallel()
+	type contact struct {
+		Name   string `gomaker:"fake[name]"`
+		Email  string `gomaker:"fake[email]"`
+		Street string `gomaker:"fake[street]"`
+		Zip    string `gomaker:"fake[zip]"`
+		Phone  string `gomaker:"fake[phone]"`
+		Office string `gomaker:"fake[phone;locale=sr_RS]"`
+		City   string `gomaker:"fake[city;locale=moon]"`
+	}
+	moon := gomaker.Locale{"cities": {"Tranquility Base"}}
+	cs, err := gomaker.MakeN[contact](gomaker.New(gomaker.WithLocale("de_DE"), gomaker.WithLocalePack("moon", moon)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := regexp.MustCompile(`^[a-z.]+\d*@example\.(com|org|net)$`)
+	for _, c := range cs {
+		if !strings.HasPrefix(c.Phone, "+49 ") && !strings.HasPrefix(c.Phone, "0") {
+			t.Fatalf("phone not german %s", c.Phone)
+		}
+		if !strings.HasPrefix(c.Office, "+381 ") && !strings.HasPrefix(c.Office, "0") {
+			t.Fatalf("office not serbian %s", c.Office)
+		}
+		if len(c.Zip) != 5 || !email.MatchString(c.Email) || c.City != "Tranquility Base" {
+			t.Fatalf("contact not assigned %+v", c)
+		}
+		if i := strings.LastIndexByte(c.Street, ' '); strings.Trim(c.Street[i+1:], "0123456789") != "" {
+			t.Fatalf("street number not last %s", c.Street)
+		}
+	}
+
+	tests := []struct {
+		name string
+		val  any
+		err  string
+	}{
+		{"unknown", &struct {
+			S string `gomaker:"fake[city;locale=xx_XX]"`
+		}{}, "field S: locale not available xx_XX"},
+		{"missing list", &struct {
+			S string `gomaker:"fake[phone;locale=moon]"`
+		}{}, "field S: locale moon missing list phones"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gomaker.New(gomaker.WithLocalePack("moon", moon)).Fill(tt.val)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("expected: %s, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMaker_identifiers(t *testing.T) {
+	t.Parallel()
+	type host struct {
+		ID       string           `gomaker:"uuid"`
+		Sortable string           `gomaker:"uuid[v7]"`
+		RawID    [16]byte         `gomaker:"uuid"`
+		Event    string           `gomaker:"ulid"`
+		RawEvent [16]byte         `gomaker:"ulid"`
+		Public   string           `gomaker:"ip"`
+		Private  netip.Addr       `gomaker:"ip[10.0.0.0/8]"`
+		Legacy   net.IP           `gomaker:"ip[192.168.0.0/16]"`
+		V6       netip.Addr       `gomaker:"ip[v6]"`
+		Raw4     [4]byte          `gomaker:"ip[172.16.0.0/12]"`
+		Peers    []netip.Addr     `gomaker:"ip[2001:db8::/32] len[2;2]"`
+		MAC      string           `gomaker:"mac"`
+		NIC      net.HardwareAddr `gomaker:"mac"`
+		Name     string           `gomaker:"hostname[domain=internal.test]"`
+		Link     string           `gomaker:"url"`
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	hs, err := gomaker.MakeN[host](gomaker.New(gomaker.WithSeed(2), gomaker.WithNow(now)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulid := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	mac := regexp.MustCompile(`^[0-9a-f]{2}(:[0-9a-f]{2}){5}$`)
+	name := regexp.MustCompile(`^([a-z][a-z0-9]{2,9}\.){1,2}internal\.test$`)
+	link := regexp.MustCompile(`^https://([a-z][a-z0-9]{2,9}\.){1,2}example\.com(/[a-z0-9]{3,8}){0,3}$`)
+	ids := map[string]bool{}
+	for _, h := range hs {
+		if !uuid.MatchString(h.ID) || ids[h.ID] || h.RawID[6]>>4 != 4 || h.RawID[8]>>6 != 2 {
+			t.Fatalf("uuid not assigned %s %x", h.ID, h.RawID)
+		}
+		ids[h.ID] = true
+		if v7 := fmt.Sprintf("%012x", now.UnixMilli()); h.Sortable[:8]+h.Sortable[9:13] != v7 || h.Sortable[14] != '7' {
+			t.Fatalf("uuid v7 not assigned %s", h.Sortable)
+		}
+		if !ulid.MatchString(h.Event) || h.Event[:10] != "01HWT0D7G0" || h.RawEvent == ([16]byte{}) {
+			t.Fatalf("ulid not assigned %s", h.Event)
+		}
+		if addr, err := netip.ParseAddr(h.Public); err != nil || !addr.Is4() {
+			t.Fatalf("ip not assigned %s", h.Public)
+		}
+		if !netip.MustParsePrefix("10.0.0.0/8").Contains(h.Private) || !h.V6.Is6() {
+			t.Fatalf("addr not assigned %s %s", h.Private, h.V6)
+		}
+		if legacy, _ := netip.AddrFromSlice(h.Legacy); !netip.MustParsePrefix("192.168.0.0/16").Contains(legacy) {
+			t.Fatalf("net.IP not assigned %s", h.Legacy)
+		}
+		if !netip.MustParsePrefix("172.16.0.0/12").Contains(netip.AddrFrom4(h.Raw4)) {
+			t.Fatalf("[4]byte not assigned %v", h.Raw4)
+		}
+		if len(h.Peers) != 2 || !netip.MustParsePrefix("2001:db8::/32").Contains(h.Peers[1]) {
+			t.Fatalf("peers not assigned %v", h.Peers)
+		}
+		if !mac.MatchString(h.MAC) || len(h.NIC) != 6 || h.NIC[0]&3 != 2 {
+			t.Fatalf("mac not assigned %s %s", h.MAC, h.NIC)
+		}
+		if !name.MatchString(h.Name) || !link.MatchString(h.Link) {
+			t.Fatalf("host not assigned %s %s", h.Name, h.Link)
+		}
+	}
+
+	bad := struct {
+		Port int `gomaker:"ip"`
+	}{}
+	if err = gomaker.New().Fill(&bad); err == nil || err.Error() != "field Port: kind not supported: int" {
+		t.Errorf("expected: field Port: kind not supported: int, got: %v", err)
+	}
+}
+
+type status string
+
+type priority int8
+
+func TestMaker_oneof(t *testing.T) {
+	t.Parallel()
+	type ticket struct {
+		Status   status    `gomaker:"oneof[PENDING,ACTIVE:3,CLOSED]"`
+		Priority priority  `gomaker:"oneof[1,2,3]"`
+		Mask     uint16    `gomaker:"oneof[0x0f,0xf0]"`
+		Ratio    float32   `gomaker:"oneof[0.25,0.5]"`
+		Done     bool      `gomaker:"oneof[true:9,false]"`
+		Tags     []string  `gomaker:"oneof[a,b,c] len[3;3]"`
+		Owner    *string   `gomaker:"oneof[alice,bob]"`
+		Level    complex64 `gomaker:"oneof[1+2i]"`
+	}
+	ts, err := gomaker.MakeN[ticket](gomaker.New(gomaker.WithSeed(4)), 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, done := map[status]int{}, 0
+	for _, tk := range ts {
+		statuses[tk.Status]++
+		if tk.Done {
+			done++
+		}
+		if tk.Priority < 1 || tk.Priority > 3 || tk.Mask != 0x0f && tk.Mask != 0xf0 || tk.Ratio != 0.25 && tk.Ratio != 0.5 {
+			t.Fatalf("numbers not assigned %+v", tk)
+		}
+		if len(tk.Tags) != 3 || *tk.Owner != "alice" && *tk.Owner != "bob" || tk.Level != 1+2i {
+			t.Fatalf("ticket not assigned %+v", tk)
+		}
+	}
+	if len(statuses) != 3 || statuses["ACTIVE"] < 2700 || statuses["ACTIVE"] > 3300 || statuses["PENDING"] < 800 {
+		t.Errorf("statuses not weighted %v", statuses)
+	}
+	if done < 4300 || done > 4700 {
+		t.Errorf("done not weighted %d", done)
+	}
+
+	tests := []struct {
+		name string
+		val  any
+		err  string
+	}{
+		{"overflow", &struct {
+			P priority `gomaker:"oneof[1,200]"`
+		}{}, `field P: oneof value 200: strconv.ParseInt: parsing "200": value out of range`},
+		{"bool", &struct {
+			B bool `gomaker:"oneof[yes,no]"`
+		}{}, `field B: oneof value yes: strconv.ParseBool: parsing "yes": invalid syntax`},
+		{"struct", &struct {
+			T time.Time `gomaker:"oneof[a]"`
+		}{}, "field T: kind not supported: struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := gomaker.New().Fill(tt.val); err == nil || err.Error() != tt.err {
+				t.Errorf("expected: %s, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMaker_generators(t *testing.T) {
+	t.Parallel()
+	generators := map[string]gomaker.Generator{
+		"between": func(ctx gomaker.Context, args ...string) (any, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("between expects 2 arguments got %d", len(args))
+			}
+			var lo, hi int64
+			if _, err := fmt.Sscan(args[0], &lo); err != nil {
+				return nil, err
+			}
+			if _, err := fmt.Sscan(args[1], &hi); err != nil {
+				return nil, err
+			}
+			return lo + ctx.Rand.Int63n(hi-lo), nil
+		},
+		"sku": func(ctx gomaker.Context, args ...string) (any, error) {
+			return fmt.Sprintf("%s-%04d", args[0], ctx.Rand.Intn(10000)), nil
+		},
+		"type": func(ctx gomaker.Context, _ ...string) (any, error) {
+			return ctx.Value.Type().String(), nil
+		},
+	}
+	type product struct {
+		Stock int64    `gomaker:"func[between;10;20]"`
+		Price int64    `gomaker:"func[between;100;200]"`
+		SKU   string   `gomaker:"func[sku;BK]"`
+		Codes []string `gomaker:"func[sku;CD] len[2;2]"`
+		Kind  string   `gomaker:"func[type]"`
+		Label string   `gomaker:"func[legacy]"`
+	}
+	m := gomaker.New(gomaker.WithSeed(6), gomaker.WithGenerators(generators), gomaker.WithFuncMap(map[string]func() any{
+		"legacy": func() any { return "old" },
+	}))
+	ps, err := gomaker.MakeN[product](m, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range ps {
+		if p.Stock < 10 || p.Stock >= 20 || p.Price < 100 || p.Price >= 200 {
+			t.Fatalf("between not assigned %+v", p)
+		}
+		if !strings.HasPrefix(p.SKU, "BK-") || len(p.Codes) != 2 || !strings.HasPrefix(p.Codes[1], "CD-") {
+			t.Fatalf("sku not assigned %+v", p)
+		}
+		if p.Kind != "string" || p.Label != "old" {
+			t.Fatalf("context not passed %+v", p)
+		}
+	}
+	m.Reset()
+	again, err := gomaker.MakeN[product](m, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ps, again) {
+		t.Error("generators not reproducible")
+	}
+
+	bad := struct {
+		Stock int64 `gomaker:"func[between;10]"`
+	}{}
+	err = gomaker.New(gomaker.WithGenerators(generators)).Fill(&bad)
+	if err == nil || err.Error() != "field Stock: fn between: between expects 2 arguments got 1" {
+		t.Errorf("expected: field Stock: fn between: between expects 2 arguments got 1, got: %v", err)
+	}
+}
+
+func TestMaker_funcResults(t *testing.T) {
+	t.Parallel()
+	type point struct {
+		X, Y int
+	}
+	type inner struct {
+		Small int8 `gomaker:"func[counter]"`
+	}
+	type shape struct {
+		Sides   priority       `gomaker:"func[counter]"`
+		Origin  point          `gomaker:"func[origin]"`
+		Tags    []string       `gomaker:"func[tags]"`
+		Labels  []string       `gomaker:"func[label] len[3;3]"`
+		Center  *point         `gomaker:"func[origin]"`
+		Anchor  *point         `gomaker:"func[originPtr]"`
+		Weights map[string]int `gomaker:"func[weights]"`
+		Counts  []*int64       `gomaker:"func[counter] len[2;2]"`
+		Missing *point         `gomaker:"func[none]"`
+		Inner   inner
+	}
+	var calls atomic.Int64
+	generators := map[string]gomaker.Generator{
+		"counter": func(gomaker.Context, ...string) (any, error) {
+			return int(calls.Add(1)), nil
+		},
+		"origin": func(gomaker.Context, ...string) (any, error) {
+			return point{X: 1, Y: 2}, nil
+		},
+		"originPtr": func(gomaker.Context, ...string) (any, error) {
+			return &point{X: 3, Y: 4}, nil
+		},
+		"tags": func(gomaker.Context, ...string) (any, error) {
+			return []string{"a", "b"}, nil
+		},
+		"label": func(ctx gomaker.Context, _ ...string) (any, error) {
+			return fmt.Sprintf("label-%d", ctx.Rand.Intn(10)), nil
+		},
+		"weights": func(gomaker.Context, ...string) (any, error) {
+			return map[string]int{"a": 1}, nil
+		},
+		"none": func(gomaker.Context, ...string) (any, error) {
+			return nil, nil
+		},
+	}
+	s, err := gomaker.Make[shape](gomaker.New(gomaker.WithGenerators(generators)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Origin != (point{1, 2}) || *s.Center != (point{1, 2}) || *s.Anchor != (point{3, 4}) || s.Missing != nil {
+		t.Errorf("structs not assigned %+v", s)
+	}
+	if !reflect.DeepEqual(s.Tags, []string{"a", "b"}) || len(s.Labels) != 3 || s.Weights["a"] != 1 {
+		t.Errorf("collections not assigned %+v", s)
+	}
+	if sum := int64(s.Sides) + *s.Counts[0] + *s.Counts[1] + int64(s.Inner.Small); calls.Load() != 4 || sum != 10 {
+		t.Errorf("counter called %d times, values summing to %d", calls.Load(), sum)
+	}
+
+	calls.Store(200)
+	_, err = gomaker.Make[shape](gomaker.New(gomaker.WithGenerators(generators)))
+	if err == nil || err.Error() != "field Inner.Small: fn counter: int 203 does not fit int8" {
+		t.Errorf("expected: field Inner.Small: fn counter: int 203 does not fit int8, got: %v", err)
+	}
+}
+
+func TestMaker_regexUniverse(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Any     string `gomaker:"regex[.{20}]"`
+		NotWord string `gomaker:"regex[[^a-z]{20}]"`
+		Greek   string `gomaker:"regex[[α-ω]{5}]"`
+	}
+	printable := func(s string) bool {
+		for _, c := range s {
+			if c < ' ' || c > '~' {
+				return false
+			}
+		}
+		return true
+	}
+	ds, err := gomaker.MakeN[dummy](gomaker.New(gomaker.WithSeed(8)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		if !printable(d.Any) || !printable(d.NotWord) || strings.ContainsAny(d.NotWord, "abcdefghijklmnopqrstuvwxyz") {
+			t.Fatalf("not printable %q %q", d.Any, d.NotWord)
+		}
+		if utf8.RuneCountInString(d.Greek) != 5 || printable(d.Greek[:2]) {
+			t.Fatalf("greek not assigned %q", d.Greek)
+		}
+	}
+
+	d, err := gomaker.Make[dummy](gomaker.New(gomaker.WithRegexUniverse("0-9A-F")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Trim(d.Any, "0123456789ABCDEF") != "" || strings.Trim(d.NotWord, "0123456789ABCDEF") != "" {
+		t.Errorf("universe not applied %q %q", d.Any, d.NotWord)
+	}
+	type german struct {
+		Word string `gomaker:"regex[[a-zäöü]{200}]"`
+	}
+	g, err := gomaker.Make[german](gomaker.New(gomaker.WithSeed(8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(g.Word, "ä") {
+		t.Errorf("umlauts dropped from %q", g.Word)
+	}
+	if err = gomaker.New(gomaker.WithRegexUniverse("z-a")).Fill(&dummy{}); err == nil ||
+		err.Error() != "field Any: regex universe: rand chars range z-a reversed" {
+		t.Errorf("expected: field Any: regex universe: rand chars range z-a reversed, got: %v", err)
+	}
+}
+
+func TestMaker_regexRepeat(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Star    string   `gomaker:"regex[a*]"`
+		AtLeast string   `gomaker:"regex[b{4,}]"`
+		Capped  []string `gomaker:"regex[c+] repeat[2] len[3;3]"`
+	}
+	ds, err := gomaker.MakeN[dummy](gomaker.New(gomaker.WithSeed(9), gomaker.WithRegexRepeat(3)), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ds {
+		if len(d.Star) > 3 || len(d.AtLeast) < 4 || len(d.AtLeast) > 7 {
+			t.Fatalf("repeat cap not applied %q %q", d.Star, d.AtLeast)
+		}
+		for _, c := range d.Capped {
+			if len(c) < 1 || len(c) > 3 {
+				t.Fatalf("repeat modifier not applied %q", c)
+			}
+		}
+	}
+
+	type long struct {
+		Text string `gomaker:"regex[x{500}]"`
+	}
+	if err = gomaker.New(gomaker.WithRegexMaxLen(100)).Fill(&long{}); err == nil || err.Error() != "field Text: regex output longer than 100" {
+		t.Errorf("expected: field Text: regex output longer than 100, got: %v", err)
+	}
+	if err = gomaker.New(gomaker.WithRegexRepeat(-1)).Fill(&dummy{}); err == nil || err.Error() != "field AtLeast: negative regex repeat -1" {
+		t.Errorf("expected: field AtLeast: negative regex repeat -1, got: %v", err)
+	}
+	type badMod struct {
+		Text string `gomaker:"rand[5] repeat[2]"`
+	}
+	if err = gomaker.New().Fill(&badMod{}); err == nil || err.Error() != "field Text: modifiers not supported on kind: string" {
+		t.Errorf("expected: field Text: modifiers not supported on kind: string, got: %v", err)
+	}
+}
+
+func TestMaker_regexFlags(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Word string `gomaker:"regex[(?i)gomaker]"`
+		Tag  string `gomaker:"regex[<(?P<tag>[a-z]{2,6})>[a-z ]{0,9}</\\k<tag>>]"`
+		Edge string `gomaker:"regex[^[ab ]{4}\\b[ab ]{4}$]"`
+	}
+	tag := regexp.MustCompile(`^<([a-z]+)>[a-z ]*</([a-z]+)>$`)
+	edge := regexp.MustCompile(`^[ab ]{4}\b[ab ]{4}$`)
+	ds, err := gomaker.MakeN[dummy](gomaker.New(gomaker.WithSeed(10)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{}
+	for _, d := range ds {
+		if !strings.EqualFold(d.Word, "gomaker") {
+			t.Fatalf("case folding not applied %q", d.Word)
+		}
+		cases[d.Word] = true
+		if m := tag.FindStringSubmatch(d.Tag); m == nil || m[1] != m[2] {
+			t.Fatalf("backreference not applied %q", d.Tag)
+		}
+		if !edge.MatchString(d.Edge) {
+			t.Fatalf("word boundary not applied %q", d.Edge)
+		}
+	}
+	if len(cases) < 2 {
+		t.Errorf("case not randomized %v", cases)
+	}
+
+	type impossible struct {
+		Text string `gomaker:"regex[a\\bb]"`
+	}
+	if err = gomaker.New().Fill(&impossible{}); err == nil || err.Error() != `field Text: regex anchors not satisfied regex[a\bb]` {
+		t.Errorf(`expected: field Text: regex anchors not satisfied regex[a\bb], got: %v`, err)
+	}
+	type unknown struct {
+		Text string `gomaker:"regex[(?P<a>x)\\k<b>]"`
+	}
+	if err = gomaker.New().Fill(&unknown{}); err == nil || err.Error() != "field Text: regex backreference to unknown group b" {
+		t.Errorf("expected: field Text: regex backreference to unknown group b, got: %v", err)
+	}
+}
+
+func TestMaker_regexKinds(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		Port    uint16      `gomaker:"regex[[1-9][0-9]{3}]"`
+		Price   float64     `gomaker:"regex[[0-9]{2}\\.[0-9]{2}]"`
+		Active  bool        `gomaker:"regex[true|false]"`
+		Code    []byte      `gomaker:"regex[[A-Z]{3}-[0-9]{2}]"`
+		Codes   [][]byte    `gomaker:"regex[[a-z]{2}] len[2;2]"`
+		Zip     int32       `gomaker:"regex[0[0-9]{4}]"`
+		Addr    netip.Addr  `gomaker:"regex[10\\.0\\.0\\.[1-9]]"`
+		AddrPtr *netip.Addr `gomaker:"regex[::[1-9a-f]]"`
+	}
+	ds, err := gomaker.MakeN[dummy](gomaker.New(gomaker.WithSeed(11)), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bools := map[bool]bool{}
+	for _, d := range ds {
+		if d.Port < 1000 || d.Price >= 100 || d.Zip >= 10000 {
+			t.Fatalf("numbers not assigned %v %v %v", d.Port, d.Price, d.Zip)
+		}
+		if !regexp.MustCompile(`^[A-Z]{3}-[0-9]{2}$`).Match(d.Code) || len(d.Codes) != 2 || len(d.Codes[1]) != 2 {
+			t.Fatalf("bytes not assigned %q %q", d.Code, d.Codes)
+		}
+		if !d.Addr.Is4() || !strings.HasPrefix(d.Addr.String(), "10.0.0.") || d.AddrPtr == nil || !d.AddrPtr.Is6() {
+			t.Fatalf("text unmarshaler not used %v %v", d.Addr, d.AddrPtr)
+		}
+		bools[d.Active] = true
+	}
+	if len(bools) != 2 {
+		t.Errorf("bool not randomized %v", bools)
+	}
+
+	type inner struct {
+		Small int8 `gomaker:"regex[1000]"`
+	}
+	type outer struct {
+		Inner inner
+	}
+	if err = gomaker.New().Fill(&outer{}); err == nil || err.Error() != `field Inner.Small: regex text "1000": value out of range` {
+		t.Errorf(`expected: field Inner.Small: regex text "1000": value out of range, got: %v`, err)
+	}
+	type badBool struct {
+		Flag bool `gomaker:"regex[maybe]"`
+	}
+	if err = gomaker.New().Fill(&badBool{}); err == nil || err.Error() != `field Flag: regex text "maybe": invalid syntax` {
+		t.Errorf(`expected: field Flag: regex text "maybe": invalid syntax, got: %v`, err)
+	}
+	type badAddr struct {
+		Addr netip.Addr `gomaker:"regex[999\\.1\\.1\\.1]"`
+	}
+	if err = gomaker.New().Fill(&badAddr{}); err == nil || !strings.HasPrefix(err.Error(), `field Addr: regex text "999.1.1.1": `) {
+		t.Errorf(`expected: field Addr: regex text "999.1.1.1": ..., got: %v`, err)
+	}
+}
@@ -0,0 +1,102 @@
+package gomaker
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// compileID parses uuid, uuid[v4], uuid[v7] and ulid. Strings get the text
+// form and [16]byte arrays the raw bytes. v7 UUIDs and ULIDs start with the
+// millisecond timestamp of now.
+func compileID(tagValue string, typeOf reflect.Type, now func() time.Time) (fillFunc, error) {
+	option := optionValueOf(tagValue)
+	args, err := getArgs(tagValue, string(option))
+	if err != nil {
+		return nil, err
+	}
+	version := "v4"
+	switch {
+	case len(args) > 1 || option == ulid && len(args) > 0:
+		return nil, fmt.Errorf("option not available %s", tagValue)
+	case len(args) == 1 && args[0] != "":
+		version = args[0]
+	}
+	if version != "v4" && version != "v7" {
+		return nil, fmt.Errorf("uuid version not available %s", version)
+	}
+	isString := typeOf.Kind() == reflect.String
+	if !isString && !isByteArray(typeOf, 16) {
+		return nil, fmt.Errorf("kind not supported: %s", typeOf.Kind().String())
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		var b [16]byte
+		if option == ulid || version == "v7" {
+			putMillis(b[:6], now())
+			r.Read(b[6:])
+		} else {
+			r.Read(b[:])
+		}
+		if option == uuid {
+			b[6] = b[6]&0x0f | (version[1]-'0')<<4
+			b[8] = b[8]&0x3f | 0x80
+		}
+		switch {
+		case !isString:
+			copy(field.Slice(0, field.Len()).Bytes(), b[:])
+		case option == ulid:
+			field.SetString(encodeULID(b))
+		default:
+			field.SetString(formatUUID(b))
+		}
+		return nil
+	}, nil
+}
+
+func isByteArray(typeOf reflect.Type, n int) bool {
+	return typeOf.Kind() == reflect.Array && typeOf.Len() == n && typeOf.Elem().Kind() == reflect.Uint8
+}
+
+// putMillis writes the unix milliseconds of t as 48 bits big endian.
+func putMillis(b []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := range b {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+}
+
+func formatUUID(b [16]byte) string {
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// encodeULID writes the 128 bits as 26 Crockford base32 characters, the
+// first holding only the top 3 bits.
+func encodeULID(b [16]byte) string {
+	var s [26]byte
+	for i := range s {
+		var v byte
+		for j := 0; j < 5; j++ {
+			bit := i*5 + j - 2
+			v <<= 1
+			if bit >= 0 && b[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		s[i] = crockford[v]
+	}
+	return string(s[:])
+}
@@ -0,0 +1,55 @@
+package gomaker
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_encodeULID(t *testing.T) {
+	t.Parallel()
+	var b [16]byte
+	if got := encodeULID(b); got != strings.Repeat("0", 26) {
+		t.Errorf("encodeULID(zero) = %s", got)
+	}
+	putMillis(b[:6], time.UnixMilli(1469918176385))
+	if got := encodeULID(b); !strings.HasPrefix(got, "01ARYZ6S41") {
+		t.Errorf("encodeULID() timestamp = %s, want prefix 01ARYZ6S41", got)
+	}
+	for i := range b {
+		b[i] = 0xff
+	}
+	if got := encodeULID(b); got != "7"+strings.Repeat("Z", 25) {
+		t.Errorf("encodeULID(max) = %s", got)
+	}
+}
+
+func Test_compileID(t *testing.T) {
+	t.Parallel()
+	now := time.UnixMilli(0x0123456789ab)
+	fill, err := compileID("uuid[v7]", reflect.TypeOf(""), func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err = fill(rand.New(rand.NewSource(1)), nil, reflect.ValueOf(&s).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s, "01234567-89ab-7") || !strings.ContainsAny(s[19:20], "89ab") {
+		t.Errorf("uuid v7 = %s", s)
+	}
+	for tag, want := range map[string]string{
+		"uuid[v1]":  "uuid version not available v1",
+		"uuid[a;b]": "option not available uuid[a;b]",
+		"ulid[v4]":  "option not available ulid[v4]",
+	} {
+		if _, err = compileID(tag, reflect.TypeOf(""), time.Now); err == nil || err.Error() != want {
+			t.Errorf("compileID(%s) expected: %s, got: %v", tag, want, err)
+		}
+	}
+	if _, err = compileID("uuid", reflect.TypeOf([8]byte{}), time.Now); err == nil || err.Error() != "kind not supported: array" {
+		t.Errorf("expected: kind not supported: array, got: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package gomaker
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+)
+
+var addrType = reflect.TypeOf(netip.Addr{})
+
+var hostAlphabet = mustAlphabet("a-z0-9")
+
+// compileIP parses ip, ip[v4], ip[v6] or ip[cidr], e.g. ip[10.0.0.0/8], and
+// draws addresses inside the prefix. Strings, netip.Addr, net.IP and byte
+// arrays of 4 bytes for IPv4 or 16 bytes are filled.
+func compileIP(tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	args, err := getArgs(tagValue, string(ipa))
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 1 {
+		return nil, fmt.Errorf("ip expects at most 1 argument got %d", len(args))
+	}
+	prefix := netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+	if len(args) == 1 {
+		switch args[0] {
+		case "", "v4":
+		case "v6":
+			prefix = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+		default:
+			if prefix, err = netip.ParsePrefix(args[0]); err != nil {
+				return nil, fmt.Errorf("ip cidr: %w", err)
+			}
+		}
+	}
+	prefix = prefix.Masked()
+	is4 := prefix.Addr().Is4()
+	switch kind := typeOf.Kind(); {
+	case kind == reflect.String:
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetString(randAddr(r, prefix).String())
+			return nil
+		}, nil
+	case typeOf.ConvertibleTo(addrType) && kind == reflect.Struct:
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.Set(reflect.ValueOf(randAddr(r, prefix)).Convert(field.Type()))
+			return nil
+		}, nil
+	case kind == reflect.Slice && typeOf.Elem().Kind() == reflect.Uint8:
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetBytes(randAddr(r, prefix).AsSlice())
+			return nil
+		}, nil
+	case isByteArray(typeOf, 16):
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			b := randAddr(r, prefix).As16()
+			copy(field.Slice(0, 16).Bytes(), b[:])
+			return nil
+		}, nil
+	case is4 && isByteArray(typeOf, 4):
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			b := randAddr(r, prefix).As4()
+			copy(field.Slice(0, 4).Bytes(), b[:])
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+}
+
+// randAddr keeps the prefix bits of the address and draws the rest.
+func randAddr(r *rand.Rand, prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	random := make([]byte, len(b))
+	r.Read(random)
+	for i := range b {
+		hostBits := min(8, max(0, 8*i+8-prefix.Bits()))
+		mask := byte(1<<hostBits - 1)
+		b[i] = b[i]&^mask | random[i]&mask
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// compileMAC fills strings, net.HardwareAddr and [6]byte arrays with a
+// locally administered unicast MAC address.
+func compileMAC(tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	if tagValue != string(macAddr) {
+		return nil, fmt.Errorf("option not available %s", tagValue)
+	}
+	kind := typeOf.Kind()
+	if kind != reflect.String && !(kind == reflect.Slice && typeOf.Elem().Kind() == reflect.Uint8) && !isByteArray(typeOf, 6) {
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		b := make(net.HardwareAddr, 6)
+		r.Read(b)
+		b[0] = b[0]&^1 | 2
+		switch kind {
+		case reflect.String:
+			field.SetString(b.String())
+		case reflect.Slice:
+			field.SetBytes(b)
+		default:
+			copy(field.Slice(0, 6).Bytes(), b)
+		}
+		return nil
+	}, nil
+}
+
+type hostOptions struct {
+	scheme, domain string
+}
+
+// compileHost parses hostname[domain=...] and url[scheme=...;domain=...].
+// Hostnames are one or two random labels under the domain, example.com by
+// default, and URLs add a random path of up to three segments.
+func compileHost(tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	if kind := typeOf.Kind(); kind != reflect.String {
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	option := optionValueOf(tagValue)
+	o, err := getHostOptions(tagValue, option)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		name := randHostname(r, o.domain)
+		if option == host {
+			field.SetString(name)
+			return nil
+		}
+		var b strings.Builder
+		b.WriteString(o.scheme + "://" + name)
+		for i := r.Intn(4); i > 0; i-- {
+			b.WriteString("/" + hostAlphabet.randString(r, 3+r.Int63n(6)))
+		}
+		field.SetString(b.String())
+		return nil
+	}, nil
+}
+
+func getHostOptions(value string, option option) (hostOptions, error) {
+	o := hostOptions{scheme: "https", domain: "example.com"}
+	args, err := getArgs(value, string(option))
+	if err != nil {
+		return o, err
+	}
+	for _, arg := range args {
+		key, val, _ := strings.Cut(arg, "=")
+		switch {
+		case key == "domain" && val != "":
+			o.domain = val
+		case key == "scheme" && val != "" && option == uri:
+			o.scheme = val
+		default:
+			return o, fmt.Errorf("%s option not available %s", option, arg)
+		}
+	}
+	return o, nil
+}
+
+// randHostname returns one or two labels of lowercase letters and digits,
+// each starting with a letter, followed by domain.
+func randHostname(r *rand.Rand, domain string) string {
+	var b strings.Builder
+	for i := 1 + r.Intn(2); i > 0; i-- {
+		b.WriteString(alphabets["lower"].randString(r, 1))
+		b.WriteString(hostAlphabet.randString(r, 2+r.Int63n(8)))
+		b.WriteByte('.')
+	}
+	return b.String() + domain
+}
@@ -0,0 +1,52 @@
+package gomaker
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+func Test_randAddr(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	for _, cidr := range []string{"10.0.0.0/8", "192.168.1.0/24", "172.16.0.0/12", "10.1.2.3/32", "2001:db8::/32", "fe80::/10", "0.0.0.0/0"} {
+		prefix := netip.MustParsePrefix(cidr)
+		seen := map[netip.Addr]bool{}
+		for i := 0; i < 100; i++ {
+			addr := randAddr(r, prefix)
+			if !prefix.Contains(addr) {
+				t.Fatalf("%s not in %s", addr, cidr)
+			}
+			seen[addr] = true
+		}
+		if prefix.Bits() < 24 && len(seen) < 90 || prefix.IsSingleIP() && len(seen) != 1 {
+			t.Errorf("%s drew %d distinct addresses", cidr, len(seen))
+		}
+	}
+}
+
+func Test_getHostOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		arg    string
+		option option
+		want   hostOptions
+		err    string
+	}{
+		{"hostname", host, hostOptions{scheme: "https", domain: "example.com"}, ""},
+		{"url[scheme=ftp;domain=test]", uri, hostOptions{scheme: "ftp", domain: "test"}, ""},
+		{"hostname[scheme=ftp]", host, hostOptions{}, "hostname option not available scheme=ftp"},
+		{"url[domain=]", uri, hostOptions{}, "url option not available domain="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := getHostOptions(tt.arg, tt.option)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("getHostOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
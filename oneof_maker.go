@@ -0,0 +1,151 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type choice struct {
+	value  string
+	weight float64
+}
+
+// compileOneOf parses oneof[A,B:3,C], picking a value with probability
+// proportional to its weight, 1 unless given after a colon. A backslash makes
+// the next character literal, e.g. oneof[a\,b,c\:d]. Values are converted to
+// typeOf once, so named string, int or float enum types work as well.
+func compileOneOf(tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	if kind := typeOf.Kind(); !isScalar(kind) {
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	choices, err := getChoices(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]reflect.Value, len(choices))
+	cumulative := make([]float64, len(choices))
+	var total float64
+	for i, c := range choices {
+		if values[i], err = parseScalar(c.value, typeOf); err != nil {
+			return nil, fmt.Errorf("oneof value %s: %w", c.value, err)
+		}
+		total += c.weight
+		cumulative[i] = total
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		i := sort.SearchFloat64s(cumulative, r.Float64()*total)
+		field.Set(values[min(i, len(values)-1)])
+		return nil
+	}, nil
+}
+
+func getChoices(value string) ([]choice, error) {
+	rest, ok := strings.CutPrefix(value, string(oneof)+"[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return nil, fmt.Errorf("option not available %s", value)
+	}
+	var choices []choice
+	for _, item := range splitEscaped(rest[:len(rest)-1], ',') {
+		c := choice{weight: 1}
+		parts := splitEscaped(item, ':')
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("oneof expects value:weight got %s", item)
+		}
+		if len(parts) == 2 {
+			w, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("oneof weight: %w", err)
+			}
+			if w <= 0 {
+				return nil, fmt.Errorf("oneof weight %v not positive", w)
+			}
+			c.weight = w
+		}
+		c.value = unescape(parts[0])
+		choices = append(choices, c)
+	}
+	if len(choices) == 1 && choices[0].value == "" {
+		return nil, errors.New("oneof expects at least one value")
+	}
+	return choices, nil
+}
+
+// splitEscaped splits s on sep, except where a backslash precedes it. The
+// backslashes are kept for unescape.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isScalar(kind reflect.Kind) bool {
+	return kind >= reflect.Bool && kind <= reflect.Complex128 || kind == reflect.String
+}
+
+// parseScalar converts text to a value of typeOf, which must have a string,
+// bool or numeric kind.
+func parseScalar(text string, typeOf reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typeOf).Elem()
+	switch kind := typeOf.Kind(); kind {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 0, typeOf.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(text, 0, typeOf.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, typeOf.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(text, typeOf.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetComplex(c)
+	default:
+		return v, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	return v, nil
+}
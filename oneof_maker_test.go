@@ -0,0 +1,51 @@
+package gomaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_getChoices(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		arg  string
+		want []choice
+		err  string
+	}{
+		{"plain", "oneof[a,b]", []choice{{"a", 1}, {"b", 1}}, ""},
+		{"weights", "oneof[PENDING,ACTIVE:3,CLOSED:0.5]", []choice{{"PENDING", 1}, {"ACTIVE", 3}, {"CLOSED", 0.5}}, ""},
+		{"escaped", `oneof[a\,b,12\:30:2]`, []choice{{"a,b", 1}, {"12:30", 2}}, ""},
+		{"empty", "oneof[]", nil, "oneof expects at least one value"},
+		{"bare", "oneof", nil, "option not available oneof"},
+		{"zero weight", "oneof[a:0]", nil, "oneof weight 0 not positive"},
+		{"bad weight", "oneof[a:x]", nil, `oneof weight: strconv.ParseFloat: parsing "x": invalid syntax`},
+		{"two colons", "oneof[a:1:2]", nil, "oneof expects value:weight got a:1:2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getChoices(tt.arg)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getChoices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getChoices_structTag(t *testing.T) {
+	t.Parallel()
+	type dummy struct {
+		At string `gomaker:"oneof[12\\:30,18\\:00]"`
+	}
+	field, _ := reflect.TypeOf(dummy{}).FieldByName("At")
+	got, err := getChoices(field.Tag.Get(tag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []choice{{"12:30", 1}, {"18:00", 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getChoices() = %v, want %v", got, want)
+	}
+}
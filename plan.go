@@ -0,0 +1,340 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+// fillFunc fills a single value. Tags are parsed once when the plan is
+// compiled, so a fillFunc only draws random data and sets it.
+type fillFunc func(r *rand.Rand, sc *scope, field reflect.Value) error
+
+// plan is the compiled form of a struct type, with fields kept in the order
+// rel dependencies require.
+type plan struct {
+	fields []fieldPlan
+}
+
+type planCache struct {
+	mu    sync.RWMutex
+	plans map[reflect.Type]*plan
+}
+
+type fieldPlan struct {
+	name  string
+	index []int
+	fill  fillFunc
+}
+
+func (m *Maker) planFor(typeOf reflect.Type) (*plan, error) {
+	m.cache.mu.RLock()
+	p, ok := m.cache.plans[typeOf]
+	m.cache.mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+	graph := m.fields
+	if len(graph) == 0 {
+		var err error
+		if graph, err = buildGraph(typeOf); err != nil {
+			return nil, err
+		}
+	}
+	p, err := m.compilePlan(nil, typeOf, graph)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.mu.Lock()
+	defer m.cache.mu.Unlock()
+	if cached, ok := m.cache.plans[typeOf]; ok {
+		return cached, nil
+	}
+	m.cache.plans[typeOf] = p
+	return p, nil
+}
+
+// compilePlan compiles the fields of typeOf found in graph. parent is the
+// struct typeOf is nested in, if any, which rel paths reach with "^".
+func (m *Maker) compilePlan(parent *typeScope, typeOf reflect.Type, graph map[string]any) (*plan, error) {
+	order, err := orderFields(graph)
+	if err != nil {
+		return nil, err
+	}
+	sc := &typeScope{typeOf: typeOf, parent: parent}
+	p := &plan{fields: make([]fieldPlan, 0, len(order))}
+	for _, name := range order {
+		field, ok := typeOf.FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("field not found %s", name)
+		}
+		fill, err := m.compileField(sc, graph[name], field.Type)
+		if err != nil {
+			return nil, fieldErr(name, err)
+		}
+		p.fields = append(p.fields, fieldPlan{name: name, index: field.Index, fill: fill})
+	}
+	return p, nil
+}
+
+// compileField compiles a graph value: a tag, or the graph of a struct whose
+// own modifiers, if any, are kept under the empty key.
+func (m *Maker) compileField(sc *typeScope, val any, typeOf reflect.Type) (fillFunc, error) {
+	switch v := val.(type) {
+	case map[string]any:
+		mods, ok := v[""]
+		if !ok {
+			return m.compile(sc, tagSpec{}, v, typeOf)
+		}
+		tagValue, ok := mods.(string)
+		if !ok {
+			return nil, fmt.Errorf("unrecognized type %v", reflect.TypeOf(mods).Kind())
+		}
+		spec, err := parseTag(tagValue)
+		if err != nil {
+			return nil, err
+		}
+		if spec.gen != "" {
+			return nil, fmt.Errorf("generator not supported on struct %s", spec.gen)
+		}
+		graph := make(map[string]any, len(v)-1)
+		for key, child := range v {
+			if key != "" {
+				graph[key] = child
+			}
+		}
+		return m.compile(sc, spec, graph, typeOf)
+	case string:
+		spec, err := parseTag(v)
+		if err != nil {
+			return nil, err
+		}
+		return m.compile(sc, spec, nil, typeOf)
+	default:
+		return nil, fmt.Errorf("unrecognized type %v", reflect.TypeOf(val).Kind())
+	}
+}
+
+// compile peels pointers and slices off typeOf, consuming their modifiers,
+// until it reaches a struct to fill from graph or a value for the generator.
+// sc is the struct holding the field.
+func (m *Maker) compile(sc *typeScope, spec tagSpec, graph map[string]any, typeOf reflect.Type) (fillFunc, error) {
+	switch kind := typeOf.Kind(); {
+	case optionValueOf(spec.gen) == fc && spec.mods["key"] == "":
+		return m.compileFunc(spec, typeOf)
+	case kind == reflect.Pointer:
+		chance := m.nilChance
+		if args, ok := spec.mods["nil"]; ok {
+			var err error
+			if chance, err = parseNilChance(args); err != nil {
+				return nil, err
+			}
+		}
+		elem, err := m.compile(sc, spec.without("nil"), graph, typeOf.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return compilePointer(elem, chance), nil
+	case kind == reflect.Slice && optionValueOf(spec.gen) != rel && !wholeBytes(spec.gen, typeOf):
+		length := m.length
+		if args, ok := spec.mods["len"]; ok {
+			var err error
+			if length, err = parseLength(args); err != nil {
+				return nil, err
+			}
+		}
+		if err := length.Validate(); err != nil {
+			return nil, err
+		}
+		elem, err := m.compile(sc, spec.without("len"), graph, typeOf.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return compileSlice(elem, length), nil
+	case kind == reflect.Array && optionValueOf(spec.gen) != rel && !wholeBytes(spec.gen, typeOf):
+		elem, err := m.compile(sc, spec, graph, typeOf.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return compileArray(elem), nil
+	case kind == reflect.Map && optionValueOf(spec.gen) != rel:
+		return m.compileMap(sc, spec, graph, typeOf)
+	case optionValueOf(spec.gen) == regex && graph == nil:
+		return m.compileRegex(spec, typeOf)
+	case len(spec.mods) != 0:
+		return nil, fmt.Errorf("modifiers not supported on kind: %s", kind.String())
+	case spec.gen == "" && kind == reflect.Struct:
+		if graph == nil {
+			var err error
+			if graph, err = buildGraph(typeOf); err != nil {
+				return nil, err
+			}
+		}
+		sub, err := m.compilePlan(sc, typeOf, graph)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+			return m.fillStruct(r, sc, field, sub)
+		}, nil
+	case graph != nil:
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	default:
+		return m.compileSimple(sc, spec.gen, typeOf)
+	}
+}
+
+// wholeBytes reports whether gen fills a byte slice or array as a whole,
+// e.g. hex on [4]byte or ip on net.IP, instead of element by element.
+func wholeBytes(gen string, typeOf reflect.Type) bool {
+	switch optionValueOf(gen) {
+	case hexa, base64:
+		return typeOf.Kind() == reflect.Array
+	case uuid, ulid, ipa, macAddr:
+		return typeOf.Elem().Kind() == reflect.Uint8
+	case regex:
+		return typeOf.Kind() == reflect.Slice && typeOf.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// compileSlice fills every element of a slice, first allocating one of
+// random length if it is empty.
+func compileSlice(elem fillFunc, length lengthRange) fillFunc {
+	return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+		if field.Len() == 0 {
+			n := length.pick(r)
+			field.Set(reflect.MakeSlice(field.Type(), n, n))
+		}
+		for i := 0; i < field.Len(); i++ {
+			if err := elem(r, sc, field.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func compileArray(elem fillFunc) fillFunc {
+	return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+		for i := 0; i < field.Len(); i++ {
+			if err := elem(r, sc, field.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (m *Maker) compileMap(sc *typeScope, spec tagSpec, graph map[string]any, typeOf reflect.Type) (fillFunc, error) {
+	length := m.length
+	if args, ok := spec.mods["map"]; ok {
+		var err error
+		if length, err = parseMapLength(args); err != nil {
+			return nil, err
+		}
+	}
+	if err := length.Validate(); err != nil {
+		return nil, err
+	}
+	keyGen, ok := spec.mods["key"]
+	if !ok {
+		return nil, errors.New("map key generator missing")
+	}
+	key, err := m.compile(sc, tagSpec{gen: keyGen}, nil, typeOf.Key())
+	if err != nil {
+		return nil, err
+	}
+	if valGen, ok := spec.mods["val"]; ok {
+		spec.gen, graph = valGen, nil
+	} else if elem := typeOf.Elem(); graph == nil && elem.Kind() != reflect.Struct &&
+		!(elem.Kind() == reflect.Pointer && elem.Elem().Kind() == reflect.Struct) {
+		return nil, errors.New("map val generator missing")
+	}
+	val, err := m.compile(sc, spec.without("map").without("key").without("val"), graph, typeOf.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return compileMapEntries(key, val, length), nil
+}
+
+// maxKeyRetries bounds how many duplicate keys in a row a map fill tolerates
+// before settling for the entries it has.
+const maxKeyRetries = 100
+
+func compileMapEntries(key, val fillFunc, length lengthRange) fillFunc {
+	return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+		typeOf := field.Type()
+		n := length.pick(r)
+		res := reflect.MakeMapWithSize(typeOf, n)
+		for retries := 0; res.Len() < n; {
+			k := reflect.New(typeOf.Key()).Elem()
+			if err := key(r, sc, k); err != nil {
+				return err
+			}
+			if res.MapIndex(k).IsValid() {
+				if retries++; retries > maxKeyRetries {
+					break
+				}
+				continue
+			}
+			retries = 0
+			v := reflect.New(typeOf.Elem()).Elem()
+			if err := val(r, sc, v); err != nil {
+				return err
+			}
+			res.SetMapIndex(k, v)
+		}
+		if res.Len() < length.min {
+			return fmt.Errorf("map got %d unique keys expected at least %d", res.Len(), length.min)
+		}
+		field.Set(res)
+		return nil
+	}
+}
+
+// compilePointer leaves the pointer nil with the given chance, otherwise
+// allocates it if needed and fills the value it points to.
+func compilePointer(elem fillFunc, chance float64) fillFunc {
+	return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+		if chance > 0 && r.Float64() < chance {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return elem(r, sc, field.Elem())
+	}
+}
+
+func (m *Maker) compileSimple(sc *typeScope, tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	switch optionValueOf(tagValue) {
+	case random:
+		return compileRandom(tagValue, typeOf, m.alphabets)
+	case rel:
+		return compileRel(sc, tagValue, typeOf)
+	case hexa, base64:
+		return compileBytes(tagValue, typeOf)
+	case tm:
+		return compileTime(tagValue, typeOf, m.now)
+	case dur:
+		return compileDuration(tagValue, typeOf)
+	case fake:
+		return compileFake(tagValue, typeOf, m.locale, m.locales)
+	case uuid, ulid:
+		return compileID(tagValue, typeOf, m.now)
+	case ipa:
+		return compileIP(tagValue, typeOf)
+	case macAddr:
+		return compileMAC(tagValue, typeOf)
+	case host, uri:
+		return compileHost(tagValue, typeOf)
+	case oneof:
+		return compileOneOf(tagValue, typeOf)
+	default:
+		return nil, fmt.Errorf("option not available %s", tagValue)
+	}
+}
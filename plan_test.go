@@ -0,0 +1,93 @@
+package gomaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_planFor(t *testing.T) {
+	t.Parallel()
+	type inner struct {
+		Value int64 `gomaker:"rand"`
+	}
+	type dummy struct {
+		Id     int64  `gomaker:"rand[1;10;1]"`
+		Copy   int64  `gomaker:"rel[copy;Id]"`
+		Name   string `gomaker:"regex[[a-z]+]"`
+		Skip   string
+		Inners []inner
+	}
+	m := New()
+	p, err := m.planFor(reflect.TypeOf(dummy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(p.fields))
+	for _, f := range p.fields {
+		names = append(names, f.name)
+	}
+	if want := []string{"Id", "Copy", "Inners", "Name"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("planFor() fields = %v, want %v", names, want)
+	}
+	again, err := m.planFor(reflect.TypeOf(dummy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != p {
+		t.Error("planFor() did not reuse cached plan")
+	}
+	if _, err = m.planFor(reflect.TypeOf(inner{})); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.cache.plans) != 2 {
+		t.Errorf("expected 2 cached plans got %d", len(m.cache.plans))
+	}
+}
+
+func Test_compilePlan_errors(t *testing.T) {
+	t.Parallel()
+	type event struct {
+		At   int64
+		Kind string
+	}
+	type dummy struct {
+		Id    int64
+		Flag  bool
+		Ratio complex64
+		Label string
+		Evs   []event
+		Inner struct {
+			Up int64 `gomaker:"rel[copy;^.Missing]"`
+		}
+	}
+	tests := []struct {
+		name  string
+		graph map[string]any
+		err   string
+	}{
+		{"missing field", map[string]any{"Missing": "rand"}, "field not found Missing"},
+		{"bad constraints", map[string]any{"Id": "rand[10;1;1]"}, "field Id: min bigger then max"},
+		{"regex kind", map[string]any{"Ratio": "regex[a]"}, "field Ratio: kind not supported: complex64"},
+		{"rel op", map[string]any{"Id": "rel[nope;Flag]"}, "field Id: rel op not available nope"},
+		{"rel copy path", map[string]any{"Id": "rel[copy]"}, "field Id: rel copy expects a path"},
+		{"rel sum kind", map[string]any{"Flag": "rel[sum;Id]"}, "field Flag: kind not supported: bool"},
+		{"rel offset kind", map[string]any{"Flag": "rel[offset;Id;1;2]"}, "field Flag: kind not supported: bool"},
+		{"rel fmt kind", map[string]any{"Id": "rel[fmt;x]"}, "field Id: rel fmt on non string kind: int64"},
+		{"rel missing path", map[string]any{"Id": "rel[sum;Evs.Missing]"}, "field Id: rel field not found Missing"},
+		{"rel fmt missing path", map[string]any{"Label": "rel[fmt;{Id}-{Name}]"}, "field Label: rel field not found Name"},
+		{"rel path through kind", map[string]any{"Id": "rel[copy;Flag.Value]"}, "field Id: rel field Value on kind: bool"},
+		{"rel escapes root", map[string]any{"Id": "rel[copy;^.Id]"}, "field Id: rel path ^.Id escapes root"},
+		{"rel nested missing", map[string]any{"Inner": map[string]any{"Up": "rel[copy;^.Missing]"}}, "field Inner.Up: rel field not found Missing"},
+		{"rel sum source", map[string]any{"Id": "rel[sum;Evs.Kind]"}, "field Id: rel sum on non numeric kind: string"},
+		{"rel offset source", map[string]any{"Id": "rel[offset;Flag;1;2]"}, "field Id: rel offset on non numeric kind: bool"},
+		{"rel copy source", map[string]any{"Flag": "rel[copy;Evs]"}, "field Flag: rel cannot assign []gomaker.event to bool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().compilePlan(nil, reflect.TypeOf(dummy{}), tt.graph)
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -6,110 +6,419 @@ import (
 	"math"
 	"math/rand"
 	"reflect"
-	"regexp"
 	"strconv"
-	"unsafe"
+	"strings"
 )
 
+// constraints are the rand[min;max;step;key=value] arguments as written.
+// They are parsed once the target kind is known, so integer bounds stay
+// exact over the whole int64 and uint64 ranges. Omitted bounds hold the
+// defaults.
 type constraints struct {
-	min, max int64
-	step     float64
+	min, max, step string
+	hasMin, hasMax bool
+	opts           map[string]string
 }
 
-var defaultConstraints = constraints{min: 1, max: 10, step: 1}
-var randomPattern = regexp.MustCompile(`\[(\d*)?;(\d*)?;(\d*\.?\d)?]`)
+var randomOptions = map[string]bool{"dist": true, "alphabet": true, "chars": true, "prefix": true, "suffix": true}
 
-const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-const (
-	letterIdxBits = 6
-	letterIdxMask = 1<<letterIdxBits - 1
-	letterIdxMax  = 63 / letterIdxBits
-)
+// stringOptions are the rand options only strings take.
+var stringOptions = []string{"alphabet", "chars", "prefix", "suffix"}
+
+var defaultConstraints = constraints{min: "1", max: "10", step: "1"}
+
+// intRange holds [min, max), or [min, max] when inclusive, which intRange
+// sets for a max at the int64 limit since one past it cannot be written.
+type intRange struct {
+	min, max  int64
+	step      uint64
+	inclusive bool
+	dist      distribution
+}
+
+// uintRange is intRange for unsigned kinds, inclusive at the uint64 limit.
+type uintRange struct {
+	min, max  uint64
+	step      uint64
+	inclusive bool
+	dist      distribution
+}
 
-func (c constraints) Validate(kind reflect.Kind) error {
-	if c.min > c.max {
-		return errors.New("min bigger then max")
+type floatRange struct {
+	min, max, step float64
+	dist           distribution
+}
+
+// intRange parses the bounds for a signed kind of the given size. max is
+// exclusive, so it may be one past the largest value of the kind, except for
+// int64 where max at the limit is inclusive. An omitted
+// bound becomes the kind's limit when its default would leave the range
+// empty, or when a distribution is given, except that exp and zipf count
+// from an omitted min of 0.
+func (c constraints) intRange(kind reflect.Kind, bits int) (intRange, error) {
+	step, err := c.wholeStep()
+	if err != nil {
+		return intRange{}, err
 	}
-	if c.step < 0 {
-		return errors.New("negative step")
+	lo, hi := int64(-1)<<(bits-1), int64(1)<<(bits-1)-1
+	res := intRange{step: step}
+	if res.min, err = strconv.ParseInt(c.min, 10, 64); err != nil {
+		return intRange{}, fmt.Errorf("rand min: %w", err)
 	}
-	switch kind {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		if math.Mod(c.step, 1) != 0 {
-			return errors.New("step not whole number for int type")
+	if res.max, err = strconv.ParseInt(c.max, 10, 64); err != nil {
+		return intRange{}, fmt.Errorf("rand max: %w", err)
+	}
+	if res.min < lo || res.min > hi {
+		return intRange{}, fmt.Errorf("rand min %d overflows %s", res.min, kind)
+	}
+	if bits < 64 && (res.max < lo || res.max > hi+1) {
+		return intRange{}, fmt.Errorf("rand max %d overflows %s", res.max, kind)
+	}
+	if (res.min > res.max || c.hasDist()) && !c.hasMax {
+		res.max = hi
+		if bits < 64 {
+			res.max++
+		}
+	}
+	if (res.min > res.max || c.hasDist()) && !c.hasMin {
+		res.min = lo
+		if c.fromMin() {
+			res.min = 0
 		}
 	}
-	return nil
+	if res.min > res.max {
+		return intRange{}, errors.New("min bigger then max")
+	}
+	res.inclusive = bits == 64 && res.max == hi
+	res.dist, err = c.distribution(float64(res.min), float64(res.max), float64(res.step))
+	return res, err
+}
+
+// uintRange is intRange for unsigned kinds.
+func (c constraints) uintRange(kind reflect.Kind, bits int) (uintRange, error) {
+	step, err := c.wholeStep()
+	if err != nil {
+		return uintRange{}, err
+	}
+	hi := uint64(math.MaxUint64) >> (64 - bits)
+	res := uintRange{step: step}
+	if res.min, err = strconv.ParseUint(c.min, 10, 64); err != nil {
+		return uintRange{}, fmt.Errorf("rand min: %w", err)
+	}
+	if res.max, err = strconv.ParseUint(c.max, 10, 64); err != nil {
+		return uintRange{}, fmt.Errorf("rand max: %w", err)
+	}
+	if res.min > hi {
+		return uintRange{}, fmt.Errorf("rand min %d overflows %s", res.min, kind)
+	}
+	if bits < 64 && res.max > hi+1 {
+		return uintRange{}, fmt.Errorf("rand max %d overflows %s", res.max, kind)
+	}
+	if (res.min > res.max || c.hasDist()) && !c.hasMax {
+		res.max = hi
+		if bits < 64 {
+			res.max++
+		}
+	}
+	if (res.min > res.max || c.hasDist()) && !c.hasMin {
+		res.min = 0
+	}
+	if res.min > res.max {
+		return uintRange{}, errors.New("min bigger then max")
+	}
+	res.inclusive = bits == 64 && res.max == hi
+	res.dist, err = c.distribution(float64(res.min), float64(res.max), float64(res.step))
+	return res, err
+}
+
+// floatRange parses the bounds for a float kind of the given size.
+func (c constraints) floatRange(kind reflect.Kind, bits int) (floatRange, error) {
+	var res floatRange
+	var err error
+	if res.min, err = strconv.ParseFloat(c.min, 64); err != nil {
+		return floatRange{}, fmt.Errorf("rand min: %w", err)
+	}
+	if res.max, err = strconv.ParseFloat(c.max, 64); err != nil {
+		return floatRange{}, fmt.Errorf("rand max: %w", err)
+	}
+	if res.step, err = strconv.ParseFloat(c.step, 64); err != nil {
+		return floatRange{}, fmt.Errorf("rand step: %w", err)
+	}
+	hi := math.MaxFloat64
+	if bits == 32 {
+		hi = math.MaxFloat32
+	}
+	if math.Abs(res.min) > hi {
+		return floatRange{}, fmt.Errorf("rand min %v overflows %s", res.min, kind)
+	}
+	if math.Abs(res.max) > hi {
+		return floatRange{}, fmt.Errorf("rand max %v overflows %s", res.max, kind)
+	}
+	if (res.min > res.max || c.hasDist()) && !c.hasMax {
+		res.max = hi
+	}
+	if (res.min > res.max || c.hasDist()) && !c.hasMin {
+		res.min = -hi
+		if c.fromMin() {
+			res.min = 0
+		}
+	}
+	if res.min > res.max {
+		return floatRange{}, errors.New("min bigger then max")
+	}
+	if res.step < 0 {
+		return floatRange{}, errors.New("negative step")
+	}
+	res.dist, err = c.distribution(res.min, res.max, res.step)
+	return res, err
+}
+
+func (c constraints) hasDist() bool {
+	return c.opts["dist"] != ""
+}
+
+// fromMin reports whether the distribution draws min plus a non-negative
+// amount, as exp and zipf do, so an omitted min is 0 rather than the kind's
+// lowest value every sample would land on.
+func (c constraints) fromMin() bool {
+	name, _, _ := strings.Cut(c.opts["dist"], "(")
+	return name == "exp" || name == "zipf"
 }
 
-func fillRandomSimple(r *rand.Rand, field reflect.Value, tagValue string) error {
-	c := getOptions(tagValue)
-	kind := field.Kind()
-	if err := c.Validate(kind); err != nil {
-		return err
+func (c constraints) distribution(min, max, step float64) (distribution, error) {
+	if !c.hasDist() {
+		return nil, nil
+	}
+	return parseDistribution(c.opts["dist"], min, max, step)
+}
+
+func (c constraints) wholeStep() (uint64, error) {
+	step, err := strconv.ParseFloat(c.step, 64)
+	if err != nil {
+		return 0, fmt.Errorf("rand step: %w", err)
+	}
+	if step < 0 {
+		return 0, errors.New("negative step")
+	}
+	if math.Mod(step, 1) != 0 {
+		return 0, errors.New("step not whole number for int type")
+	}
+	if step == 0 {
+		return 1, nil
+	}
+	return uint64(step), nil
+}
+
+func (c constraints) alphabet(named map[string]string) (alphabet, error) {
+	name, hasName := c.opts["alphabet"]
+	chars, hasChars := c.opts["chars"]
+	switch {
+	case hasName && hasChars:
+		return alphabet{}, errors.New("rand alphabet and chars are exclusive")
+	case hasChars:
+		return parseAlphabet(chars)
+	case !hasName:
+		return defaultAlphabet, nil
+	}
+	if chars, ok := named[name]; ok {
+		a, err := parseAlphabet(chars)
+		if err != nil {
+			return alphabet{}, fmt.Errorf("alphabet %s: %w", name, err)
+		}
+		return a, nil
+	}
+	if a, ok := alphabets[name]; ok {
+		return a, nil
+	}
+	return alphabet{}, fmt.Errorf("alphabet not available %s", name)
+}
+
+// compileRandom compiles rand for typeOf. named holds the alphabets
+// registered with WithAlphabet, which take precedence over the built in ones.
+func compileRandom(tagValue string, typeOf reflect.Type, named map[string]string) (fillFunc, error) {
+	c, err := getOptions(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	kind := typeOf.Kind()
+	for _, key := range stringOptions {
+		if _, ok := c.opts[key]; ok && kind != reflect.String {
+			return nil, fmt.Errorf("rand option %s not supported on kind: %s", key, kind.String())
+		}
 	}
 	switch kind {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		field.SetInt(randInt64(r, c))
+		ir, err := c.intRange(kind, typeOf.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetInt(randInt64(r, ir))
+			return nil
+		}, nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		field.SetUint(uint64(randInt64(r, c)))
+		ur, err := c.uintRange(kind, typeOf.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetUint(randUint64(r, ur))
+			return nil
+		}, nil
 	case reflect.Float32, reflect.Float64:
-		field.SetFloat(randFloat64(r, c))
+		fr, err := c.floatRange(kind, typeOf.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetFloat(randFloat64(r, fr))
+			return nil
+		}, nil
 	case reflect.Complex64, reflect.Complex128:
-		field.SetComplex(complex(randFloat64(r, c), randFloat64(r, c)))
+		fr, err := c.floatRange(kind, typeOf.Bits()/2)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetComplex(complex(randFloat64(r, fr), randFloat64(r, fr)))
+			return nil
+		}, nil
 	case reflect.String:
-		field.SetString(randString(r, randInt64(r, c)))
+		ir, err := c.intRange(reflect.Int, 64)
+		if err != nil {
+			return nil, err
+		}
+		if ir.min < 0 {
+			return nil, errors.New("negative string length")
+		}
+		a, err := c.alphabet(named)
+		if err != nil {
+			return nil, err
+		}
+		prefix, suffix := c.opts["prefix"], c.opts["suffix"]
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetString(prefix + a.randString(r, randInt64(r, ir)) + suffix)
+			return nil
+		}, nil
 	case reflect.Bool:
-		field.SetBool(r.Float64() < 0.5)
+		if c.hasDist() {
+			return nil, fmt.Errorf("dist not supported on kind: %s", kind.String())
+		}
+		return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+			field.SetBool(r.Float64() < 0.5)
+			return nil
+		}, nil
 	default:
-		return fmt.Errorf("kind not supported: %s", kind.String())
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
 	}
-	return nil
 }
 
-func getOptions(value string) constraints {
-	matches := randomPattern.FindStringSubmatch(value)
+func getOptions(value string) (constraints, error) {
+	args, err := getArgs(value, string(random))
+	if err != nil {
+		return constraints{}, err
+	}
 	c := defaultConstraints
-	if len(matches) == 4 {
-		if tmp, err := strconv.Atoi(matches[1]); err == nil {
-			c.min = int64(tmp)
+	c.opts = map[string]string{}
+	bounds := []*string{&c.min, &c.max, &c.step}
+	positional := 0
+	for _, arg := range args {
+		if key, val, isOption := strings.Cut(arg, "="); isOption {
+			if !randomOptions[key] {
+				return constraints{}, fmt.Errorf("rand option not available %s", key)
+			}
+			c.opts[key] = val
+			continue
 		}
-		if tmp, err := strconv.Atoi(matches[2]); err == nil {
-			c.max = int64(tmp)
+		if positional == len(bounds) {
+			return constraints{}, fmt.Errorf("rand expects at most 3 arguments got %d", positional+1)
 		}
-		if tmp, err := strconv.ParseFloat(matches[3], 32); err == nil {
-			c.step = tmp
+		if arg != "" {
+			*bounds[positional] = arg
+			c.hasMin = c.hasMin || positional == 0
+			c.hasMax = c.hasMax || positional == 1
 		}
+		positional++
 	}
-	return c
+	return c, nil
 }
 
-func randInt64(r *rand.Rand, in constraints) int64 {
-	res := randFloat64(r, in)
-	return int64(res)
+// randInt64 returns min, or a value min+k*step below max.
+func randInt64(r *rand.Rand, in intRange) int64 {
+	if in.dist != nil {
+		x := sampleIn(r, in.dist, float64(in.min), float64(in.max), float64(in.step))
+		v := max(int64(math.Floor(x)), in.min)
+		switch {
+		case in.inclusive:
+			v = min(v, in.max)
+		case in.max > in.min:
+			v = min(v, in.max-1)
+		}
+		return v - int64((uint64(v)-uint64(in.min))%in.step)
+	}
+	span := uint64(in.max) - uint64(in.min)
+	if in.inclusive {
+		return in.min + int64(randStepTo(r, span, in.step))
+	}
+	return in.min + int64(randStep(r, span, in.step))
 }
 
-func randFloat64(r *rand.Rand, in constraints) float64 {
-	scale := r.Float64()*float64(in.max-in.min) + float64(in.min)
-	mod := math.Mod(scale, in.step)
-	res := (scale - mod) * in.step
-	return res
+func randUint64(r *rand.Rand, in uintRange) uint64 {
+	if in.dist != nil {
+		x := sampleIn(r, in.dist, float64(in.min), float64(in.max), float64(in.step))
+		v := max(uint64(math.Floor(x)), in.min)
+		switch {
+		case in.inclusive:
+			v = min(v, in.max)
+		case in.max > in.min:
+			v = min(v, in.max-1)
+		}
+		return v - (v-in.min)%in.step
+	}
+	if in.inclusive {
+		return in.min + randStepTo(r, in.max-in.min, in.step)
+	}
+	return in.min + randStep(r, in.max-in.min, in.step)
 }
 
-func randString(r *rand.Rand, n int64) string {
-	b := make([]byte, n)
-	for i, cache, remain := n-1, r.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = r.Int63(), letterIdxMax
-		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			b[i] = letterBytes[idx]
-			i--
+// randStep returns k*step for a uniform k with k*step below span, or 0 for
+// an empty span.
+func randStep(r *rand.Rand, span, step uint64) uint64 {
+	count := span / step
+	if span%step != 0 {
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return randUint64n(r, count) * step
+}
+
+// randStepTo returns k*step for a uniform k with k*step at most span.
+func randStepTo(r *rand.Rand, span, step uint64) uint64 {
+	count := span/step + 1
+	if count == 0 {
+		// span is MaxUint64 and step 1, so every value is possible
+		return r.Uint64()
+	}
+	return randUint64n(r, count) * step
+}
+
+// randUint64n returns a uniform value in [0, n), rejecting the values that
+// would bias the modulo.
+func randUint64n(r *rand.Rand, n uint64) uint64 {
+	rem := (math.MaxUint64%n + 1) % n
+	for {
+		v := r.Uint64()
+		if rem == 0 || v < -rem {
+			return v % n
 		}
-		cache >>= letterIdxBits
-		remain--
 	}
+}
 
-	return *(*string)(unsafe.Pointer(&b))
+func randFloat64(r *rand.Rand, in floatRange) float64 {
+	if in.dist != nil {
+		return quantize(sampleIn(r, in.dist, in.min, in.max, in.step), in.min, in.step)
+	}
+	return quantize(uniform{max: in.max}.sample(r, in.min, in.step), in.min, in.step)
 }
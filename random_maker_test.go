@@ -1,7 +1,9 @@
 package gomaker
 
 import (
+	"math"
 	"math/rand"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -11,42 +13,77 @@ func Test_getOptions(t *testing.T) {
 	tests := []struct {
 		name  string
 		arg   string
-		want  int64
-		want1 int64
-		want2 float64
+		want  string
+		want1 string
+		want2 string
+		err   string
 	}{
 		{
 			"nothing",
 			"rand",
-			1,
-			10,
-			1,
+			"1",
+			"10",
+			"1",
+			"",
 		},
 		{
 			"full",
 			"rand[2;11;.5]",
-			2,
-			11,
-			0.5,
+			"2",
+			"11",
+			".5",
+			"",
 		},
 		{
 			"min only",
 			"rand[2;;]",
-			2,
-			10,
-			1,
+			"2",
+			"10",
+			"1",
+			"",
 		},
 		{
 			"max only",
 			"rand[;11;]",
-			1,
-			11,
-			1,
+			"1",
+			"11",
+			"1",
+			"",
+		},
+		{
+			"negative",
+			"rand[-1.5;2.5;0.25]",
+			"-1.5",
+			"2.5",
+			"0.25",
+			"",
+		},
+		{
+			"unclosed",
+			"rand[1;2",
+			"",
+			"",
+			"",
+			"option not available rand[1;2",
+		},
+		{
+			"too many",
+			"rand[1;2;3;4]",
+			"",
+			"",
+			"",
+			"rand expects at most 3 arguments got 4",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getOptions(tt.arg)
+			got, err := getOptions(tt.arg)
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+				return
+			}
 			if got.min != tt.want {
 				t.Errorf("getOptions() got = %v, want %v", got.min, tt.want)
 			}
@@ -60,41 +97,138 @@ func Test_getOptions(t *testing.T) {
 	}
 }
 
+func Test_constraints(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		arg  constraints
+		err  string
+	}{
+		{"int", constraints{min: "-50", max: "50", step: "1", hasMin: true, hasMax: true}, ""},
+		{"bad min", constraints{min: "x", max: "50", step: "1", hasMin: true, hasMax: true}, `rand min: strconv.ParseInt: parsing "x": invalid syntax`},
+		{"fraction", constraints{min: "1.5", max: "50", step: "1", hasMin: true, hasMax: true}, `rand min: strconv.ParseInt: parsing "1.5": invalid syntax`},
+		{"reversed", constraints{min: "5", max: "1", step: "1", hasMin: true, hasMax: true}, "min bigger then max"},
+		{"fraction step", constraints{min: "1", max: "5", step: "0.5", hasMin: true, hasMax: true}, "step not whole number for int type"},
+		{"negative step", constraints{min: "1", max: "5", step: "-1", hasMin: true, hasMax: true}, "negative step"},
+		{"overflow", constraints{min: "1", max: "1000", step: "1", hasMin: true, hasMax: true}, "rand max 1000 overflows int8"},
+		{"exclusive max", constraints{min: "-128", max: "128", step: "1", hasMin: true, hasMax: true}, ""},
+		{"min overflow", constraints{min: "-129", max: "10", step: "1", hasMin: true}, "rand min -129 overflows int8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.arg.intRange(reflect.Int8, 8)
+			if err != nil && err.Error() != tt.err || err == nil && tt.err != "" {
+				t.Fatalf("expected: %v, got: %v", tt.err, err)
+			}
+		})
+	}
+	clamped, err := (constraints{min: "100", max: "10", step: "1", hasMin: true}).intRange(reflect.Int8, 8)
+	if err != nil || clamped.min != 100 || clamped.max != 128 {
+		t.Errorf("intRange() = %v, %v expected max clamped to int8", clamped, err)
+	}
+	clamped, err = (constraints{min: "1", max: "-5", step: "1", hasMax: true}).intRange(reflect.Int8, 8)
+	if err != nil || clamped.min != -128 || clamped.max != -5 {
+		t.Errorf("intRange() = %v, %v expected min clamped to int8", clamped, err)
+	}
+	if _, err := (constraints{min: "-1", max: "5", step: "1"}).uintRange(reflect.Uint64, 64); err == nil {
+		t.Error("expected error for negative unsigned bound")
+	}
+	got, err := (constraints{min: "0", max: "18446744073709551615", step: "1"}).uintRange(reflect.Uint64, 64)
+	if err != nil || got.max != 1<<64-1 || !got.inclusive {
+		t.Errorf("uintRange() = %v, %v", got, err)
+	}
+	full, err := (constraints{min: "-9223372036854775808", max: "9223372036854775807", step: "1"}).intRange(reflect.Int64, 64)
+	if err != nil || !full.inclusive {
+		t.Errorf("intRange() = %v, %v expected inclusive max", full, err)
+	}
+}
+
+func Test_inclusiveLimits(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(7))
+	ints, uints := map[int64]bool{}, map[uint64]bool{}
+	for i := 0; i < 200; i++ {
+		ints[randInt64(r, intRange{min: math.MaxInt64 - 1, max: math.MaxInt64, step: 1, inclusive: true})] = true
+		uints[randUint64(r, uintRange{min: math.MaxUint64 - 2, max: math.MaxUint64, step: 2, inclusive: true})] = true
+	}
+	if len(ints) != 2 || !ints[math.MaxInt64] {
+		t.Errorf("expected MaxInt64 - 1 and MaxInt64 got %v", ints)
+	}
+	if len(uints) != 2 || !uints[math.MaxUint64] {
+		t.Errorf("expected MaxUint64 - 2 and MaxUint64 got %v", uints)
+	}
+	if v := randStepTo(r, math.MaxUint64, 1); v == 0 {
+		t.Errorf("randStepTo() = %d over the whole range", v)
+	}
+}
+
 func Test_randInt64(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		name string
-		args constraints
+		args intRange
 		want func(in int64) bool
 	}{
 		{
 			"normal",
-			constraints{min: 1, max: 10, step: 1},
+			intRange{min: 1, max: 10, step: 1},
 			func(in int64) bool {
 				return in >= 1 && in < 10
 			},
 		},
 		{
-			"wrong step",
-			constraints{min: 1, max: 10, step: 0.1},
+			"step",
+			intRange{min: 1, max: 10, step: 3},
 			func(in int64) bool {
-				return in == 0
+				return in == 1 || in == 4 || in == 7
 			},
 		},
 		{
 			"same length",
-			constraints{min: 10, max: 10, step: 1},
+			intRange{min: 10, max: 10, step: 1},
 			func(in int64) bool {
 				return in == 10
 			},
 		},
+		{
+			"negative",
+			intRange{min: -50, max: -40, step: 1},
+			func(in int64) bool {
+				return in >= -50 && in < -40
+			},
+		},
+		{
+			"full range",
+			intRange{min: -1 << 63, max: 1<<63 - 1, step: 1},
+			func(in int64) bool {
+				return true
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := randInt64(rand.New(rand.NewSource(time.Now().Unix())), tt.args)
-			if !tt.want(got) {
-				t.Errorf("randInt64() = %v", got)
+			r := rand.New(rand.NewSource(time.Now().Unix()))
+			for i := 0; i < 100; i++ {
+				got := randInt64(r, tt.args)
+				if !tt.want(got) {
+					t.Errorf("randInt64() = %v", got)
+				}
 			}
 		})
 	}
 }
+
+func Test_randFloat64(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	in := floatRange{min: -1.5, max: 2.5, step: 0.25}
+	for i := 0; i < 100; i++ {
+		got := randFloat64(r, in)
+		if got < in.min || got >= in.max {
+			t.Fatalf("randFloat64() = %v out of range", got)
+		}
+		if k := (got - in.min) / in.step; k != float64(int(k)) {
+			t.Fatalf("randFloat64() = %v not a step from min", got)
+		}
+	}
+}
@@ -1,43 +1,263 @@
 package gomaker
 
 import (
-	"bytes"
+	"encoding"
 	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"regexp"
 	"regexp/syntax"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 var regexPattern = regexp.MustCompile(`^regex\[.*]$`)
 
 var generationFailed = errors.New("generator failed")
 
-func fillRegexSimple(r *rand.Rand, field reflect.Value, tagValue string) error {
-	if !regexPattern.MatchString(tagValue) {
-		return errors.New("regex validation failed")
+// defaultUniverse holds the printable ASCII characters negated classes and .
+// draw from unless WithRegexUniverse sets another set.
+const defaultUniverse = " -~"
+
+// defaultRepeat is how many times more than their minimum *, + and {n,} may
+// repeat unless WithRegexRepeat or the repeat modifier sets another cap.
+const defaultRepeat = 10
+
+// defaultMaxLen bounds the runes a single regex value may have, so nested
+// quantifiers or a large repeat cap fail instead of exhausting memory.
+const defaultMaxLen = 1 << 16
+
+// compileRegex compiles regex[pattern] with an optional repeat[n] modifier.
+// . and negated classes are limited to the characters they share with the
+// universe, so [^a-z] and . stay printable, unless they share none. Classes
+// listing their characters, as [a-zäöü], are kept as written.
+func (m *Maker) compileRegex(spec tagSpec, typeOf reflect.Type) (fillFunc, error) {
+	tagValue := spec.gen
+	repeat := m.repeat
+	for key, arg := range spec.mods {
+		if key != "repeat" {
+			return nil, fmt.Errorf("modifiers not supported on kind: %s", typeOf.Kind().String())
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("regex repeat: %w", err)
+		}
+		repeat = n
+	}
+	if repeat < 0 {
+		return nil, fmt.Errorf("negative regex repeat %d", repeat)
+	}
+	prog, err := m.regexes.get(tagValue, m.universe)
+	if err != nil {
+		return nil, err
+	}
+	if !regexKind(typeOf) {
+		return nil, fmt.Errorf("kind not supported: %s", typeOf.Kind().String())
 	}
+	maxLen := m.maxLen
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		g := regexGens.Get().(*regexGen)
+		defer g.release()
+		g.r, g.repeat, g.maxLen = r, repeat, maxLen
+		for attempt := 0; ; attempt++ {
+			if attempt == maxAttempts {
+				return fmt.Errorf("regex anchors not satisfied %s", tagValue)
+			}
+			g.reset(prog.groups)
+			if err := g.generate(prog.root); err != nil {
+				return err
+			}
+			if !prog.asserts || g.satisfied() {
+				break
+			}
+		}
+		if err := fillRegexSimple(field, g.out); err != nil {
+			var ne *strconv.NumError
+			if errors.As(err, &ne) {
+				err = ne.Err
+			}
+			return fmt.Errorf("regex text %q: %w", g.out, err)
+		}
+		return nil
+	}, nil
+}
 
-	parsedRegex, err := getParsedRegex(tagValue)
+// regexCache holds the compiled patterns of a Maker, so fields sharing a
+// pattern share its regexProg.
+type regexCache struct {
+	mu    sync.RWMutex
+	progs map[regexKey]*regexProg
+}
+
+type regexKey struct {
+	pattern, universe string
+}
+
+func (c *regexCache) get(tagValue, universe string) (*regexProg, error) {
+	key := regexKey{pattern: tagValue, universe: universe}
+	c.mu.RLock()
+	prog, ok := c.progs[key]
+	c.mu.RUnlock()
+	if ok {
+		return prog, nil
+	}
+	prog, err := compileProg(tagValue, universe)
 	if err != nil {
-		return errors.New("regex parse failed")
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.progs[key]; ok {
+		return cached, nil
 	}
+	c.progs[key] = prog
+	return prog, nil
+}
+
+// regexProg is a pattern compiled for generation. groups counts the named
+// groups, asserts tells whether the pattern has anchors or word boundaries.
+type regexProg struct {
+	root    *regexNode
+	groups  int
+	asserts bool
+}
 
-	result, err := generate(r, parsedRegex)
+func compileProg(tagValue, universe string) (*regexProg, error) {
+	if !regexPattern.MatchString(tagValue) {
+		return nil, errors.New("regex validation failed")
+	}
+	parsedRegex, err := getParsedRegex(tagValue)
 	if err != nil {
-		return err
+		return nil, errors.New("regex parse failed")
+	}
+	u, err := parseAlphabet(universe)
+	if err != nil {
+		return nil, fmt.Errorf("regex universe: %w", err)
+	}
+	restrict(parsedRegex, u.pairs())
+	groups := map[string]int{}
+	for _, name := range parsedRegex.CapNames() {
+		if _, isRef := refName(name); name != "" && !isRef {
+			groups[name] = len(groups)
+		}
+	}
+	root, err := newRegexNode(parsedRegex, groups)
+	if err != nil {
+		return nil, err
+	}
+	return &regexProg{root: root, groups: len(groups), asserts: hasAssertions(parsedRegex)}, nil
+}
+
+// regexNode is a node of a compiled pattern, with literals encoded, class
+// sizes counted and ?, * and + turned into repeats once, not on every value.
+type regexNode struct {
+	op       syntax.Op
+	text     []byte // OpLiteral
+	runes    int    // runes in text
+	class    []rune // OpCharClass lo, hi pairs
+	total    int    // runes in class
+	min, max int    // OpRepeat, max -1 when unbounded
+	group    int    // OpCapture, index of a named group or -1
+	ref      int    // OpCapture, index of the group a backreference repeats or -1
+	sub      []*regexNode
+}
+
+// newRegexNode compiles re, groups numbering its named groups.
+func newRegexNode(re *syntax.Regexp, groups map[string]int) (*regexNode, error) {
+	n := &regexNode{op: re.Op, group: -1, ref: -1}
+	switch re.Op {
+	case syntax.OpStar:
+		n.op, n.min, n.max = syntax.OpRepeat, 0, -1
+	case syntax.OpPlus:
+		n.op, n.min, n.max = syntax.OpRepeat, 1, -1
+	case syntax.OpQuest:
+		n.op, n.min, n.max = syntax.OpRepeat, 0, 1
+	case syntax.OpRepeat:
+		n.min, n.max = re.Min, re.Max
+	case syntax.OpLiteral:
+		n.text, n.runes = []byte(string(re.Rune)), len(re.Rune)
+	case syntax.OpCharClass:
+		n.class = re.Rune
+		for i := 0; i < len(re.Rune); i += 2 {
+			n.total += int(re.Rune[i+1]-re.Rune[i]) + 1
+		}
+	case syntax.OpCapture:
+		if ref, ok := refName(re.Name); ok {
+			if n.ref, ok = groups[ref]; !ok {
+				return nil, fmt.Errorf("regex backreference to unknown group %s", ref)
+			}
+		} else if re.Name != "" {
+			n.group = groups[re.Name]
+		}
+	}
+	for _, sub := range re.Sub {
+		s, err := newRegexNode(sub, groups)
+		if err != nil {
+			return nil, err
+		}
+		n.sub = append(n.sub, s)
 	}
+	return n, nil
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// regexKind reports whether fillRegexSimple can set a value of typeOf.
+func regexKind(typeOf reflect.Type) bool {
+	switch typeOf.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Slice:
+		return typeOf.Elem().Kind() == reflect.Uint8
+	}
+	return reflect.PointerTo(typeOf).Implements(textUnmarshaler)
+}
 
+// fillRegexSimple sets field from the generated text, through UnmarshalText
+// when the field implements encoding.TextUnmarshaler. Numbers are parsed in
+// base 10, so leading zeros as in \d{3} are kept out of the value.
+func fillRegexSimple(field reflect.Value, text []byte) error {
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshaler) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(text)
+	}
+	result := string(text)
 	kind := field.Kind()
 	switch kind {
 	case reflect.String:
 		field.SetString(result)
+	case reflect.Slice:
+		field.SetBytes([]byte(result))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(result)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, _ := strconv.ParseInt(result, 10, 64)
+		i, err := strconv.ParseInt(result, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
 		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(result, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(result, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
 	default:
 		return fmt.Errorf("kind not supported: %s", kind.String())
 	}
@@ -47,106 +267,349 @@ func fillRegexSimple(r *rand.Rand, field reflect.Value, tagValue string) error {
 func getParsedRegex(value string) (*syntax.Regexp, error) {
 	value = strings.TrimPrefix(value, "regex[")
 	value = strings.TrimSuffix(value, "]")
-	parse, err := syntax.Parse(value, syntax.Perl)
+	parse, err := syntax.Parse(expandRefs(value), syntax.Perl)
 	if err != nil {
 		return nil, err
 	}
-	return parse.Simplify(), nil
+	return parse, nil
 }
 
-func generate(r *rand.Rand, parsedRegex *syntax.Regexp) (string, error) {
-	switch parsedRegex.Op {
-	case syntax.OpStar:
-		return repeatingGenerator(r, parsedRegex.Sub[0], 0, 10)
-	case syntax.OpPlus:
-		return repeatingGenerator(r, parsedRegex.Sub[0], 1, 10)
-	case syntax.OpQuest:
-		return repeatingGenerator(r, parsedRegex.Sub[0], 0, 1)
+// refPrefix names the empty groups expandRefs puts in place of \k<name>.
+const refPrefix = "gomaker_ref"
+
+// expandRefs rewrites each \k<name> backreference, which syntax does not
+// know, into an empty group named refPrefix, its index, _ and name, e.g.
+// (?P<gomaker_ref0_tag>).
+func expandRefs(pattern string) string {
+	var b strings.Builder
+	refs := 0
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '\\' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		if rest, ok := strings.CutPrefix(pattern[i+1:], "k<"); ok {
+			if name, _, ok := strings.Cut(rest, ">"); ok {
+				fmt.Fprintf(&b, "(?P<%s%d_%s>)", refPrefix, refs, name)
+				refs++
+				i += len(name) + 3
+				continue
+			}
+		}
+		b.WriteString(pattern[i : i+2])
+		i++
+	}
+	return b.String()
+}
+
+// refName returns the group a backreference group refers to.
+func refName(capName string) (string, bool) {
+	rest, ok := strings.CutPrefix(capName, refPrefix)
+	if !ok {
+		return "", false
+	}
+	_, name, ok := strings.Cut(rest, "_")
+	return name, ok
+}
+
+// maxAttempts is how many values a pattern with anchors or word boundaries
+// may generate before one satisfying them is given up on.
+const maxAttempts = 100
+
+func hasAssertions(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasAssertions(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// regexGen appends a value matching a pattern to out. Unbounded quantifiers
+// repeat at most repeat times more than their minimum and out may hold at most
+// maxLen runes. Named groups are kept in captures for backreferences, and
+// anchors and word boundaries in asserts, to be checked once out is complete.
+// regexGens keeps them, and so their buffers, for reuse.
+type regexGen struct {
+	r        *rand.Rand
+	repeat   int
+	maxLen   int
+	out      []byte
+	runes    int
+	captures []span
+	asserts  []assertion
+}
+
+// span is the part out[start:end] a named group generated.
+type span struct {
+	start, end int
+}
+
+var regexGens = sync.Pool{New: func() any { return new(regexGen) }}
+
+// release returns g to regexGens, unless its buffer grew too large to keep.
+func (g *regexGen) release() {
+	g.r = nil
+	if cap(g.out) <= 1<<16 {
+		regexGens.Put(g)
+	}
+}
+
+// assertion is an empty width operator met at byte pos of out.
+type assertion struct {
+	op  syntax.Op
+	pos int
+}
+
+func (g *regexGen) reset(groups int) {
+	g.out, g.runes, g.asserts = g.out[:0], 0, g.asserts[:0]
+	if cap(g.captures) < groups {
+		g.captures = make([]span, groups)
+	}
+	g.captures = g.captures[:groups]
+	clear(g.captures)
+}
+
+// satisfied reports whether out meets every assertion met generating it.
+func (g *regexGen) satisfied() bool {
+	for _, a := range g.asserts {
+		var before, after rune = -1, -1
+		if a.pos > 0 {
+			before, _ = utf8.DecodeLastRune(g.out[:a.pos])
+		}
+		if a.pos < len(g.out) {
+			after, _ = utf8.DecodeRune(g.out[a.pos:])
+		}
+		var ok bool
+		switch a.op {
+		case syntax.OpBeginText:
+			ok = a.pos == 0
+		case syntax.OpEndText:
+			ok = a.pos == len(g.out)
+		case syntax.OpBeginLine:
+			ok = before == -1 || before == '\n'
+		case syntax.OpEndLine:
+			ok = after == -1 || after == '\n'
+		case syntax.OpWordBoundary:
+			ok = syntax.IsWordChar(before) != syntax.IsWordChar(after)
+		case syntax.OpNoWordBoundary:
+			ok = syntax.IsWordChar(before) == syntax.IsWordChar(after)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *regexGen) generate(n *regexNode) error {
+	switch n.op {
 	case syntax.OpRepeat:
-		return repeatingGenerator(r, parsedRegex.Sub[0], parsedRegex.Min, parsedRegex.Max)
+		return g.repeating(n)
 	case syntax.OpAlternate:
-		return alternateGenerator(r, parsedRegex)
+		return g.generate(n.sub[g.r.Intn(len(n.sub))])
 	case syntax.OpCharClass:
-		return charClassGenerator(r, parsedRegex)
+		return g.charClass(n)
 	case syntax.OpCapture:
-		return captureGenerator(r, parsedRegex)
-	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
-		return charGenerator(r, parsedRegex)
+		return g.capture(n)
 	case syntax.OpLiteral:
-		return literalGenerator(r, parsedRegex)
+		return g.write(n.text, n.runes)
 	case syntax.OpConcat:
-		return concatGenerator(r, parsedRegex)
-	case syntax.OpEndText, syntax.OpEndLine, syntax.OpBeginLine, syntax.OpBeginText, syntax.OpNoWordBoundary, syntax.OpWordBoundary, syntax.OpEmptyMatch:
-		return emptyGenerator(r, parsedRegex)
+		for _, sub := range n.sub {
+			if err := g.generate(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpEndText, syntax.OpEndLine, syntax.OpBeginLine, syntax.OpBeginText, syntax.OpNoWordBoundary, syntax.OpWordBoundary:
+		g.asserts = append(g.asserts, assertion{op: n.op, pos: len(g.out)})
+		return nil
+	case syntax.OpEmptyMatch:
+		return nil
 	default:
-		return "", fmt.Errorf("op didnt match %s", parsedRegex.Op.String())
+		return fmt.Errorf("op didnt match %s", n.op.String())
 	}
 }
 
-func repeatingGenerator(r *rand.Rand, parsedRegex *syntax.Regexp, min, max int) (string, error) {
-	var buff bytes.Buffer
-	repeat := r.Intn(max-min+1) + min
+// repeating generates the sub node between min and max times, at most repeat
+// times more than min when max is -1.
+func (g *regexGen) repeating(n *regexNode) error {
+	max := n.max
+	if max < 0 {
+		max = n.min + g.repeat
+	}
+	repeat := g.r.Intn(max-n.min+1) + n.min
 	for i := 0; i < repeat; i++ {
-		s, err := generate(r, parsedRegex)
-		if err != nil {
-			return "", err
+		if err := g.generate(n.sub[0]); err != nil {
+			return err
 		}
-		buff.WriteString(s)
 	}
-	return buff.String(), nil
+	return nil
 }
 
-func literalGenerator(_ *rand.Rand, parsedRegex *syntax.Regexp) (string, error) {
-	if parsedRegex != nil {
-		return string(parsedRegex.Rune), nil
+// capture generates a group, keeping where its value is when named, or writes
+// the value of the group a backreference refers to, empty if it was not
+// reached.
+func (g *regexGen) capture(n *regexNode) error {
+	if n.ref >= 0 {
+		c := g.captures[n.ref]
+		return g.write(g.out[c.start:c.end], utf8.RuneCount(g.out[c.start:c.end]))
+	}
+	start := len(g.out)
+	if err := g.generate(n.sub[0]); err != nil {
+		return err
 	}
-	return "", generationFailed
+	if n.group >= 0 {
+		g.captures[n.group] = span{start: start, end: len(g.out)}
+	}
+	return nil
 }
 
-func charGenerator(r *rand.Rand, _ *syntax.Regexp) (string, error) {
-	return randString(r, 1), nil
+func (g *regexGen) write(b []byte, runes int) error {
+	if err := g.count(runes); err != nil {
+		return err
+	}
+	g.out = append(g.out, b...)
+	return nil
 }
-func emptyGenerator(_ *rand.Rand, _ *syntax.Regexp) (string, error) {
-	return "", nil
+
+// count adds runes to the length of out, failing past maxLen.
+func (g *regexGen) count(runes int) error {
+	g.runes += runes
+	if g.maxLen > 0 && g.runes > g.maxLen {
+		return fmt.Errorf("regex output longer than %d", g.maxLen)
+	}
+	return nil
 }
 
-func concatGenerator(r *rand.Rand, parsedRegex *syntax.Regexp) (string, error) {
-	var buff bytes.Buffer
-	for _, subRegex := range parsedRegex.Sub {
-		s, err := generate(r, subRegex)
-		if err != nil {
-			return "", generationFailed
+// charClass picks a rune uniformly from all the class ranges, so every
+// character is as likely as any other.
+func (g *regexGen) charClass(n *regexNode) error {
+	if n.total == 0 {
+		return generationFailed
+	}
+	k := g.r.Intn(n.total)
+	for i := 0; i < len(n.class); i += 2 {
+		size := int(n.class[i+1]-n.class[i]) + 1
+		if k < size {
+			if err := g.count(1); err != nil {
+				return err
+			}
+			g.out = utf8.AppendRune(g.out, n.class[i]+rune(k))
+			return nil
+		}
+		k -= size
+	}
+	return generationFailed
+}
+
+// restrict turns . into a class of universe, without \n unless (?s) is set,
+// and limits negated classes, the ones reaching unicode.MaxRune such as [^a-z]
+// or \D, to the runes they share with universe, leaving those sharing none as
+// they are. Case insensitive classes lose the case variants outside universe
+// of runes having one inside, so (?i)k gives no Kelvin sign. Other classes are
+// kept as written, so [a-zäöü] yields umlauts. universe holds sorted lo, hi
+// pairs.
+func restrict(re *syntax.Regexp, universe []rune) {
+	if re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase != 0 {
+		foldCase(re)
+	}
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		ranges := universe
+		if re.Op == syntax.OpAnyCharNotNL {
+			ranges = intersect(ranges, []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune})
+		}
+		re.Op, re.Rune = syntax.OpCharClass, ranges
+	case syntax.OpCharClass:
+		switch {
+		case len(re.Rune) > 0 && re.Rune[len(re.Rune)-1] == unicode.MaxRune:
+			if ranges := intersect(re.Rune, universe); len(ranges) > 0 {
+				re.Rune = ranges
+			}
+		case re.Flags&syntax.FoldCase != 0:
+			re.Rune = dropFolds(re.Rune, universe)
 		}
-		buff.WriteString(s)
+	}
+	for _, sub := range re.Sub {
+		restrict(sub, universe)
+	}
+}
 
+// dropFolds removes from class the runes outside universe having a case
+// variant inside it.
+func dropFolds(class, universe []rune) []rune {
+	var res []rune
+	for i := 0; i < len(class); i += 2 {
+		for c := class[i]; c <= class[i+1]; c++ {
+			if !contains(universe, c) && foldInside(c, universe) {
+				continue
+			}
+			if n := len(res); n > 0 && res[n-1] == c-1 {
+				res[n-1] = c
+			} else {
+				res = append(res, c, c)
+			}
+		}
 	}
-	return buff.String(), nil
+	return res
 }
 
-func charClassGenerator(r *rand.Rand, parsedRegex *syntax.Regexp) (string, error) {
-	runes := parsedRegex.Rune
-	rLen := len(runes) / 2
-	res := make([]rune, rLen)
-	for i := 0; i < rLen; i++ {
-		n := r.Int31n(runes[i*2+1]-runes[i*2]+1) + runes[i*2]
-		res[i] = n
+func foldInside(c rune, universe []rune) bool {
+	for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+		if contains(universe, f) {
+			return true
+		}
 	}
-	return string(res[r.Intn(len(res))]), nil
+	return false
 }
 
-func alternateGenerator(r *rand.Rand, parsedRegex *syntax.Regexp) (string, error) {
-	sub := parsedRegex.Sub
-	res := make([]string, 0, len(sub))
-	for i := 0; i < len(sub); i++ {
-		s, err := generate(r, sub[i])
-		if err != nil {
-			return "", err
+// contains reports whether the sorted lo, hi pairs hold c.
+func contains(pairs []rune, c rune) bool {
+	i := sort.Search(len(pairs)/2, func(i int) bool { return pairs[2*i+1] >= c })
+	return i < len(pairs)/2 && pairs[2*i] <= c
+}
+
+// foldCase turns a case insensitive literal into a class, or a concatenation
+// of classes, holding every case of its runes, so (?i)ab yields aB or Ab too.
+func foldCase(re *syntax.Regexp) {
+	classes := make([]*syntax.Regexp, len(re.Rune))
+	for i, c := range re.Rune {
+		folds := []rune{c}
+		for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+			folds = append(folds, f)
+		}
+		sort.Slice(folds, func(i, j int) bool { return folds[i] < folds[j] })
+		class := make([]rune, 0, 2*len(folds))
+		for _, f := range folds {
+			class = append(class, f, f)
 		}
-		res = append(res, s)
+		classes[i] = &syntax.Regexp{Op: syntax.OpCharClass, Rune: class, Flags: syntax.FoldCase}
 	}
-	return res[r.Intn(len(sub))], nil
+	if len(classes) == 1 {
+		*re = *classes[0]
+		return
+	}
+	re.Op, re.Rune, re.Flags, re.Sub = syntax.OpConcat, nil, 0, classes
 }
 
-func captureGenerator(r *rand.Rand, parsedRegex *syntax.Regexp) (string, error) {
-	return generate(r, parsedRegex.Sub[0])
+// intersect returns the pairs of runes both sorted lo, hi pair lists hold.
+func intersect(a, b []rune) []rune {
+	var res []rune
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		lo, hi := max(a[i], b[j]), min(a[i+1], b[j+1])
+		if lo <= hi {
+			res = append(res, lo, hi)
+		}
+		if a[i+1] < b[j+1] {
+			i += 2
+		} else {
+			j += 2
+		}
+	}
+	return res
 }
@@ -4,7 +4,9 @@ import (
 	"math/rand"
 	"regexp"
 	"regexp/syntax"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func Test_generate(t *testing.T) {
@@ -46,6 +48,10 @@ func Test_generate(t *testing.T) {
 			`https?:\/\/(www\.)?[-a-zA-Z0-9@:%._\+~#=]{2,256}\.[a-z]{2,6}([-a-zA-Z0-9@:%_\+.~#?&\/\/=])`,
 		},
 	}
+	universe, err := parseAlphabet(defaultUniverse)
+	if err != nil {
+		t.Fatal(err)
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			parsedRegex, err := syntax.Parse(tt.regex, syntax.Perl)
@@ -53,8 +59,10 @@ func Test_generate(t *testing.T) {
 				t.Errorf("%v got: %v", tt.name, err)
 				return
 			}
-			r := rand.New(rand.NewSource(12345))
-			s, err := generate(r, parsedRegex)
+			restrict(parsedRegex, universe.pairs())
+			g := regexGen{r: rand.New(rand.NewSource(12345)), repeat: defaultRepeat}
+			err = g.generate(mustNode(t, parsedRegex))
+			s := string(g.out)
 			if err != nil {
 				t.Errorf("%v got: %v", tt.name, err)
 				return
@@ -67,3 +75,235 @@ func Test_generate(t *testing.T) {
 		})
 	}
 }
+
+func Test_charClass(t *testing.T) {
+	t.Parallel()
+	parsed, err := getParsedRegex("regex[[a-zA-Z0-9_]]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := mustNode(t, parsed)
+	r := rand.New(rand.NewSource(1))
+	const n = 63000
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		g := regexGen{r: r}
+		if err := g.charClass(node); err != nil {
+			t.Fatal(err)
+		}
+		counts[string(g.out)]++
+	}
+	if len(counts) != 63 {
+		t.Fatalf("expected 63 characters got %d", len(counts))
+	}
+	for c, count := range counts {
+		if count < 800 || count > 1200 {
+			t.Errorf("%s drawn %d times, expected about 1000", c, count)
+		}
+	}
+}
+
+func Test_restrict(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		regex    string
+		universe string
+		allowed  string
+	}{
+		{`[^a-z]`, defaultUniverse, " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`{|}~"},
+		{`.`, "a-c\n", "abc"},
+		{`(?s:.)`, "a-c\n", "abc\n"},
+		{`[^0-9]`, "0-9a-b", "ab"},
+		{`[α-γ]`, defaultUniverse, "αβγ"},
+		{`\D`, "0-9a", "a"},
+		{`[a-zäöü]`, defaultUniverse, "abcdefghijklmnopqrstuvwxyzäöü"},
+		{`[0-9α]`, "0-9", "0123456789α"},
+		{`(?i)k`, defaultUniverse, "kK"},
+		{`(?i)[a-cs]`, defaultUniverse, "abcsABCS"},
+		{`(?i)ä`, defaultUniverse, "äÄ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regex, func(t *testing.T) {
+			parsed, err := syntax.Parse(tt.regex, syntax.Perl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restrict(parsed, mustAlphabet(tt.universe).pairs())
+			node := mustNode(t, parsed)
+			r := rand.New(rand.NewSource(2))
+			seen := map[rune]bool{}
+			for i := 0; i < 2000; i++ {
+				g := regexGen{r: r}
+				if err := g.generate(node); err != nil {
+					t.Fatal(err)
+				}
+				for _, c := range string(g.out) {
+					seen[c] = true
+				}
+			}
+			if len(seen) != len([]rune(tt.allowed)) {
+				t.Errorf("drew %d distinct runes expected %d", len(seen), len([]rune(tt.allowed)))
+			}
+			for c := range seen {
+				if !strings.ContainsRune(tt.allowed, c) {
+					t.Errorf("drew %q outside %q", c, tt.allowed)
+				}
+			}
+		})
+	}
+}
+
+func Test_repeating(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		regex    string
+		repeat   int
+		min, max int
+	}{
+		{`a*`, defaultRepeat, 0, 10},
+		{`a+`, defaultRepeat, 1, 11},
+		{`a{3,}`, defaultRepeat, 3, 13},
+		{`a{3,}`, 0, 3, 3},
+		{`a*`, 2, 0, 2},
+		{`a{2,4}`, 0, 2, 4},
+		{`(ab){2,}`, 1, 4, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regex, func(t *testing.T) {
+			parsed, err := syntax.Parse(tt.regex, syntax.Perl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			node := mustNode(t, parsed)
+			r := rand.New(rand.NewSource(3))
+			seen := map[int]bool{}
+			for i := 0; i < 1000; i++ {
+				g := regexGen{r: r, repeat: tt.repeat}
+				if err := g.generate(node); err != nil {
+					t.Fatal(err)
+				}
+				n := len(g.out)
+				if n < tt.min || n > tt.max {
+					t.Fatalf("length %d outside %d-%d", n, tt.min, tt.max)
+				}
+				seen[n] = true
+			}
+			if !seen[tt.min] || !seen[tt.max] {
+				t.Errorf("lengths %d and %d not both drawn: %v", tt.min, tt.max, seen)
+			}
+		})
+	}
+}
+
+func Test_regexGen_maxLen(t *testing.T) {
+	t.Parallel()
+	parsed, err := syntax.Parse(`(é{100}){10}`, syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := regexGen{r: rand.New(rand.NewSource(4)), maxLen: 500}
+	if err := g.generate(mustNode(t, parsed)); err == nil || err.Error() != "regex output longer than 500" {
+		t.Fatalf("expected length error got %v", err)
+	}
+	if n := utf8.RuneCount(g.out); n != 500 {
+		t.Errorf("generated %d runes before failing expected 500", n)
+	}
+}
+
+func Test_expandRefs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		pattern, want string
+	}{
+		{`(?P<a>x)\k<a>`, `(?P<a>x)(?P<gomaker_ref0_a>)`},
+		{`\\k<a>\k<b>\k<a>`, `\\k<a>(?P<gomaker_ref0_b>)(?P<gomaker_ref1_a>)`},
+		{`\d\k<a`, `\d\k<a`},
+		{`a\`, `a\`},
+	}
+	for _, tt := range tests {
+		if got := expandRefs(tt.pattern); got != tt.want {
+			t.Errorf("expandRefs(%q) = %q expected %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func Test_regexGen_flags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		regex string
+		check func(string) bool
+	}{
+		{`(?i)abc`, func(s string) bool { return strings.EqualFold(s, "abc") }},
+		{`(?i)k`, func(s string) bool { return s == "k" || s == "K" }},
+		{`<(?P<tag>[a-z]{1,5})>x</\k<tag>>`, func(s string) bool {
+			m := regexp.MustCompile(`^<([a-z]+)>x</([a-z]+)>$`).FindStringSubmatch(s)
+			return m != nil && m[1] == m[2]
+		}},
+		{`^[a ]{3}\b[a ]{3}$`, regexp.MustCompile(`^[a ]{3}\b[a ]{3}$`).MatchString},
+		{`(?m)[a\n]{2}^b$[a\n]{2}`, regexp.MustCompile(`(?m)^[a\n]{2}^b$[a\n]{2}$`).MatchString},
+		{`[a ]{2}\B[a ]{2}`, regexp.MustCompile(`^[a ]{2}\B[a ]{2}$`).MatchString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.regex, func(t *testing.T) {
+			prog, err := compileProg("regex["+tt.regex+"]", defaultUniverse+"\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rand.New(rand.NewSource(5))
+			seen := map[string]bool{}
+			for i := 0; i < 200; i++ {
+				g := regexGen{r: r, repeat: defaultRepeat}
+				for !g.satisfied() || len(g.out) == 0 {
+					g.reset(prog.groups)
+					if err := g.generate(prog.root); err != nil {
+						t.Fatal(err)
+					}
+				}
+				s := string(g.out)
+				if !tt.check(s) {
+					t.Fatalf("%q does not match", s)
+				}
+				seen[s] = true
+			}
+			if len(seen) < 2 {
+				t.Errorf("only generated %v", seen)
+			}
+		})
+	}
+}
+
+func mustNode(t *testing.T, re *syntax.Regexp) *regexNode {
+	t.Helper()
+	n, err := newRegexNode(re, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func Test_regexCache(t *testing.T) {
+	t.Parallel()
+	type first struct {
+		Code string `gomaker:"regex[[A-Z]{3}]"`
+	}
+	type second struct {
+		Code  []string `gomaker:"regex[[A-Z]{3}] len[2;2]"`
+		Other string   `gomaker:"regex[[a-z]{3}]"`
+	}
+	m := New()
+	if err := m.Fill(&first{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Fill(&second{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.regexes.progs) != 2 {
+		t.Errorf("expected 2 cached patterns got %d", len(m.regexes.progs))
+	}
+	a, _ := m.regexes.get("regex[[A-Z]{3}]", defaultUniverse)
+	b, _ := m.regexes.get("regex[[A-Z]{3}]", defaultUniverse)
+	c, _ := m.regexes.get("regex[[A-Z]{3}]", "A-C")
+	if a != b || a == c {
+		t.Errorf("patterns not cached by pattern and universe")
+	}
+}
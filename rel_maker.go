@@ -0,0 +1,540 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const parentSegment = "^"
+
+var relPlaceholder = regexp.MustCompile(`\{([^{}]+)}`)
+
+// scope links a struct being filled to the struct that contains it, so rel
+// paths can walk up with "^".
+type scope struct {
+	value  reflect.Value
+	parent *scope
+}
+
+// typeScope is the compile time counterpart of scope, linking a struct type
+// to the one it is nested in.
+type typeScope struct {
+	typeOf reflect.Type
+	parent *typeScope
+}
+
+// compileRel compiles rel[op;args] for typeOf, a field of the struct sc,
+// rejecting paths that lead nowhere and targets op cannot set before any
+// value is filled.
+func compileRel(sc *typeScope, tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	op, args := getRelArgs(tagValue)
+	var source reflect.Type
+	switch op {
+	case "copy", "sum", "offset":
+		if len(args) > 0 && args[0] == "" {
+			return nil, fmt.Errorf("rel %s expects a path", op)
+		}
+		var err error
+		if source, err = resolveType(sc, args[0]); err != nil {
+			return nil, err
+		}
+	}
+	switch op {
+	case "copy":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("rel copy expects 1 argument got %d", len(args))
+		}
+		path := args[0]
+		if !source.AssignableTo(typeOf) && !source.ConvertibleTo(typeOf) {
+			return nil, fmt.Errorf("rel cannot assign %s to %s", source.String(), typeOf.String())
+		}
+		return func(_ *rand.Rand, sc *scope, field reflect.Value) error {
+			v, err := resolveOne(sc, path)
+			if err != nil {
+				return err
+			}
+			return assignValue(field, v)
+		}, nil
+	case "sum":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("rel sum expects 1 argument got %d", len(args))
+		}
+		if !isRelNumber(typeOf.Kind()) {
+			return nil, fmt.Errorf("kind not supported: %s", typeOf.Kind().String())
+		}
+		path := args[0]
+		for source.Kind() == reflect.Slice || source.Kind() == reflect.Array {
+			source = source.Elem()
+		}
+		if !isRelNumber(source.Kind()) {
+			return nil, fmt.Errorf("rel sum on non numeric kind: %s", source.Kind().String())
+		}
+		return func(_ *rand.Rand, sc *scope, field reflect.Value) error {
+			values, err := resolvePath(sc, path)
+			if err != nil {
+				return err
+			}
+			var total relTotal
+			for _, v := range flatten(values) {
+				if !total.add(v) {
+					return fmt.Errorf("rel sum on non numeric kind: %s", v.Kind().String())
+				}
+			}
+			return total.set(field)
+		}, nil
+	case "offset":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("rel offset expects 3 arguments got %d", len(args))
+		}
+		switch {
+		case isTime(typeOf):
+			if !isTime(source) {
+				return nil, fmt.Errorf("rel offset on non time kind: %s", source.String())
+			}
+			return compileTimeOffset(args)
+		case !isRelNumber(typeOf.Kind()):
+			return nil, fmt.Errorf("kind not supported: %s", typeOf.Kind().String())
+		case !isRelNumber(source.Kind()):
+			return nil, fmt.Errorf("rel offset on non numeric kind: %s", source.Kind().String())
+		}
+		path := args[0]
+		c := intRange{step: 1}
+		var err error
+		if c.min, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("rel offset min: %w", err)
+		}
+		if c.max, err = strconv.ParseInt(args[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("rel offset max: %w", err)
+		}
+		if c.min > c.max {
+			return nil, errors.New("min bigger then max")
+		}
+		return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+			v, err := resolveOne(sc, path)
+			if err != nil {
+				return err
+			}
+			var total relTotal
+			if !total.add(v) {
+				return fmt.Errorf("rel offset on non numeric kind: %s", v.Kind().String())
+			}
+			total.addInt(randInt64(r, c))
+			return total.set(field)
+		}, nil
+	case "fmt":
+		if typeOf.Kind() != reflect.String {
+			return nil, fmt.Errorf("rel fmt on non string kind: %s", typeOf.Kind().String())
+		}
+		template := args[0]
+		for _, path := range relPaths(tagValue) {
+			if _, err := resolveType(sc, path); err != nil {
+				return nil, err
+			}
+		}
+		return func(_ *rand.Rand, sc *scope, field reflect.Value) error {
+			var err error
+			res := relPlaceholder.ReplaceAllStringFunc(template, func(s string) string {
+				v, e := resolveOne(sc, s[1:len(s)-1])
+				if e != nil {
+					err = e
+					return ""
+				}
+				return fmt.Sprint(v.Interface())
+			})
+			if err != nil {
+				return err
+			}
+			field.SetString(res)
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("rel op not available %s", op)
+	}
+}
+
+// compileTimeOffset compiles rel[offset;path;min;max] for a time.Time field,
+// min and max being durations such as 1h or 30d.
+func compileTimeOffset(args []string) (fillFunc, error) {
+	path := args[0]
+	minOffset, err := parseDuration(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("rel offset min: %w", err)
+	}
+	maxOffset, err := parseDuration(args[2])
+	if err != nil {
+		return nil, fmt.Errorf("rel offset max: %w", err)
+	}
+	if minOffset > maxOffset {
+		return nil, errors.New("min bigger then max")
+	}
+	c := intRange{min: int64(minOffset), max: int64(maxOffset), step: 1}
+	return func(r *rand.Rand, sc *scope, field reflect.Value) error {
+		v, err := resolveOne(sc, path)
+		if err != nil {
+			return err
+		}
+		if !isTime(v.Type()) {
+			return fmt.Errorf("rel offset on non time kind: %s", v.Type().String())
+		}
+		base := v.Convert(timeType).Interface().(time.Time)
+		res := base.Add(time.Duration(randInt64(r, c)))
+		field.Set(reflect.ValueOf(res).Convert(field.Type()))
+		return nil
+	}, nil
+}
+
+func getRelArgs(value string) (string, []string) {
+	value = strings.TrimPrefix(value, "rel[")
+	value = strings.TrimSuffix(value, "]")
+	op, rest, _ := strings.Cut(value, ";")
+	if op == "fmt" {
+		return op, []string{rest}
+	}
+	return op, strings.Split(rest, ";")
+}
+
+func relPaths(tagValue string) []string {
+	op, args := getRelArgs(tagValue)
+	switch op {
+	case "copy", "sum", "offset":
+		return args[:1]
+	case "fmt":
+		matches := relPlaceholder.FindAllStringSubmatch(args[0], -1)
+		paths := make([]string, 0, len(matches))
+		for _, match := range matches {
+			paths = append(paths, match[1])
+		}
+		return paths
+	}
+	return nil
+}
+
+func resolveOne(sc *scope, path string) (reflect.Value, error) {
+	values, err := resolvePath(sc, path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if len(values) != 1 {
+		return reflect.Value{}, fmt.Errorf("rel path %s resolved to %d values", path, len(values))
+	}
+	return values[0], nil
+}
+
+func resolvePath(sc *scope, path string) ([]reflect.Value, error) {
+	segments := strings.Split(path, ".")
+	for len(segments) > 0 && segments[0] == parentSegment {
+		if sc.parent == nil {
+			return nil, fmt.Errorf("rel path %s escapes root", path)
+		}
+		sc = sc.parent
+		segments = segments[1:]
+	}
+	values := []reflect.Value{sc.value}
+	for _, name := range segments {
+		next := make([]reflect.Value, 0, len(values))
+		for _, v := range values {
+			var err error
+			if next, err = appendField(next, v, name); err != nil {
+				return nil, err
+			}
+		}
+		values = next
+	}
+	res := values[:0]
+	for _, v := range values {
+		for v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Pointer {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+// resolveType is resolvePath on types, returning the type of the values path
+// leads to from sc once pointers are dereferenced.
+func resolveType(sc *typeScope, path string) (reflect.Type, error) {
+	segments := strings.Split(path, ".")
+	for len(segments) > 0 && segments[0] == parentSegment {
+		if sc.parent == nil {
+			return nil, fmt.Errorf("rel path %s escapes root", path)
+		}
+		sc = sc.parent
+		segments = segments[1:]
+	}
+	typeOf := sc.typeOf
+	for _, name := range segments {
+		for typeOf.Kind() == reflect.Pointer || typeOf.Kind() == reflect.Slice || typeOf.Kind() == reflect.Array {
+			typeOf = typeOf.Elem()
+		}
+		if typeOf.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("rel field %s on kind: %s", name, typeOf.Kind().String())
+		}
+		field, ok := typeOf.FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("rel field not found %s", name)
+		}
+		typeOf = field.Type
+	}
+	for typeOf.Kind() == reflect.Pointer {
+		typeOf = typeOf.Elem()
+	}
+	return typeOf, nil
+}
+
+func appendField(dst []reflect.Value, v reflect.Value, name string) ([]reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return dst, nil
+		}
+		return appendField(dst, v.Elem(), name)
+	case reflect.Slice, reflect.Array:
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if dst, err = appendField(dst, v.Index(i), name); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case reflect.Struct:
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			return nil, fmt.Errorf("rel field not found %s", name)
+		}
+		return append(dst, f), nil
+	default:
+		return nil, fmt.Errorf("rel field %s on kind: %s", name, v.Kind().String())
+	}
+}
+
+func flatten(values []reflect.Value) []reflect.Value {
+	res := make([]reflect.Value, 0, len(values))
+	for _, v := range values {
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				res = append(res, flatten([]reflect.Value{v.Index(i)})...)
+			}
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+func isTime(typeOf reflect.Type) bool {
+	return typeOf.Kind() == reflect.Struct && typeOf.ConvertibleTo(timeType)
+}
+
+func isRelNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+// relTotal adds numbers for sum and offset. Integers are added exactly, as
+// the sums of the non negative and of the negative values, floats apart.
+type relTotal struct {
+	pos, neg uint64
+	float    float64
+	overflow bool
+}
+
+// add adds v, reporting false when it is not a number.
+func (t *relTotal) add(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		t.addInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		t.addUint(v.Uint(), false)
+	case reflect.Float32, reflect.Float64:
+		t.float += v.Float()
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *relTotal) addInt(n int64) {
+	if n < 0 {
+		t.addUint(uint64(-n), true)
+	} else {
+		t.addUint(uint64(n), false)
+	}
+}
+
+func (t *relTotal) addUint(n uint64, negative bool) {
+	sum := &t.pos
+	if negative {
+		sum = &t.neg
+	}
+	var carry uint64
+	*sum, carry = bits.Add64(*sum, n, 0)
+	t.overflow = t.overflow || carry != 0
+}
+
+// set sets field to the total, integer fields taking the float part
+// truncated.
+func (t *relTotal) set(field reflect.Value) error {
+	kind := field.Kind()
+	if isFloat(kind) {
+		n := float64(t.pos) - float64(t.neg) + t.float
+		if field.OverflowFloat(n) {
+			return fmt.Errorf("rel value %v overflows %s", n, kind)
+		}
+		field.SetFloat(n)
+		return nil
+	}
+	if f := math.Trunc(t.float); f != 0 {
+		if math.Abs(f) >= math.MaxUint64 {
+			return fmt.Errorf("rel value %v overflows %s", f, kind)
+		}
+		if f < 0 {
+			t.addUint(uint64(-f), true)
+		} else {
+			t.addUint(uint64(f), false)
+		}
+		t.float = 0
+	}
+	if t.overflow {
+		return fmt.Errorf("rel value overflows %s", kind)
+	}
+	negative, n := t.neg > t.pos, t.pos-t.neg
+	if negative {
+		n = t.neg - t.pos
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// -n wraps to the two's complement, so 1<<63 gives MinInt64
+		v := int64(n)
+		if negative {
+			v = int64(-n)
+		}
+		if !negative && n > math.MaxInt64 || negative && n > 1<<63 || field.OverflowInt(v) {
+			return fmt.Errorf("rel value %s overflows %s", t.text(), kind)
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if negative || field.OverflowUint(n) {
+			return fmt.Errorf("rel value %s overflows %s", t.text(), kind)
+		}
+		field.SetUint(n)
+	default:
+		return fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	return nil
+}
+
+func (t *relTotal) text() string {
+	if t.neg > t.pos {
+		return "-" + strconv.FormatUint(t.neg-t.pos, 10)
+	}
+	return strconv.FormatUint(t.pos-t.neg, 10)
+}
+
+func assignValue(field, v reflect.Value) error {
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case v.Type().ConvertibleTo(field.Type()):
+		field.Set(v.Convert(field.Type()))
+	default:
+		return fmt.Errorf("rel cannot assign %s to %s", v.Type().String(), field.Type().String())
+	}
+	return nil
+}
+
+// orderFields returns the keys of fields sorted so every key comes after the
+// siblings its rel tags depend on, failing on dependency cycles.
+func orderFields(fields map[string]any) ([]string, error) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	order := make([]string, 0, len(keys))
+	state := make(map[string]int, len(keys))
+	var stack []string
+	var visit func(key string) error
+	visit = func(key string) error {
+		switch state[key] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for stack[start] != key {
+				start++
+			}
+			cycle := append(append([]string{}, stack[start:]...), key)
+			return fmt.Errorf("rel cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+		state[key] = visiting
+		stack = append(stack, key)
+		deps := fieldDeps(fields[key], 0)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, ok := fields[dep]; !ok || dep == key && isMap(fields[key]) {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = done
+		order = append(order, key)
+		return nil
+	}
+	for _, key := range keys {
+		if err := visit(key); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// fieldDeps lists the sibling names val refers to once its rel paths, found
+// depth levels below, climb back to the level being ordered.
+func fieldDeps(val any, depth int) []string {
+	var deps []string
+	switch v := val.(type) {
+	case string:
+		spec, err := parseTag(v)
+		if err != nil || optionValueOf(spec.gen) != rel {
+			return nil
+		}
+		for _, path := range relPaths(spec.gen) {
+			segments := strings.Split(path, ".")
+			ups := 0
+			for ups < len(segments) && segments[ups] == parentSegment {
+				ups++
+			}
+			if ups == depth && ups < len(segments) {
+				deps = append(deps, segments[ups])
+			}
+		}
+	case map[string]any:
+		for _, child := range v {
+			deps = append(deps, fieldDeps(child, depth+1)...)
+		}
+	}
+	return deps
+}
+
+func isMap(val any) bool {
+	_, ok := val.(map[string]any)
+	return ok
+}
@@ -0,0 +1,67 @@
+package gomaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_orderFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		fields map[string]any
+		want   []string
+		err    string
+	}{
+		{
+			"no rel",
+			map[string]any{"B": "rand", "A": "rand"},
+			[]string{"A", "B"},
+			"",
+		},
+		{
+			"sibling",
+			map[string]any{"A": "rel[copy;C]", "B": "rand", "C": "rel[fmt;{B}]"},
+			[]string{"B", "C", "A"},
+			"",
+		},
+		{
+			"parent",
+			map[string]any{"Inner": map[string]any{"X": "rel[copy;^.Z]"}, "Z": "rand"},
+			[]string{"Z", "Inner"},
+			"",
+		},
+		{
+			"child",
+			map[string]any{"A": "rel[sum;Inner.X]", "Inner": map[string]any{"X": "rand"}},
+			[]string{"Inner", "A"},
+			"",
+		},
+		{
+			"self",
+			map[string]any{"A": "rel[copy;A]"},
+			nil,
+			"rel cycle detected: A -> A",
+		},
+		{
+			"cycle through parent",
+			map[string]any{"A": "rel[sum;Inner.X]", "Inner": map[string]any{"X": "rel[copy;^.A]"}},
+			nil,
+			"rel cycle detected: A -> Inner -> A",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderFields(tt.fields)
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderFields() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+var modifiers = map[string]bool{"len": true, "nil": true, "map": true, "repeat": true}
+
+// assignModifiers take a whole generator as argument, e.g. key=rand[1;5;1].
+var assignModifiers = map[string]bool{"key": true, "val": true}
+
+// tagSpec is a tag split into its generator, e.g. rand[1;10;1], and the
+// modifiers following it, e.g. len[1;5] stored as len -> "1;5".
+type tagSpec struct {
+	gen  string
+	mods map[string]string
+}
+
+func parseTag(tagValue string) (tagSpec, error) {
+	spec := tagSpec{mods: map[string]string{}}
+	for _, token := range splitTag(tagValue) {
+		name, args, isMod := strings.Cut(token, "[")
+		if isMod && modifiers[name] && strings.HasSuffix(args, "]") {
+			spec.mods[name] = strings.TrimSuffix(args, "]")
+			continue
+		}
+		if name, gen, isAssign := strings.Cut(token, "="); isAssign && assignModifiers[name] {
+			spec.mods[name] = gen
+			continue
+		}
+		if spec.gen != "" {
+			return spec, fmt.Errorf("multiple generators %s %s", spec.gen, token)
+		}
+		spec.gen = token
+	}
+	return spec, nil
+}
+
+// without returns a copy of s lacking the named modifier.
+func (s tagSpec) without(name string) tagSpec {
+	res := tagSpec{gen: s.gen, mods: make(map[string]string, len(s.mods))}
+	for key, args := range s.mods {
+		if key != name {
+			res.mods[key] = args
+		}
+	}
+	return res
+}
+
+// splitTag splits on spaces outside brackets, so generator arguments such as
+// regex patterns may contain spaces.
+func splitTag(tagValue string) []string {
+	var tokens []string
+	depth, start := 0, 0
+	for i := 0; i < len(tagValue); i++ {
+		switch tagValue[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ' ':
+			if depth == 0 {
+				if i > start {
+					tokens = append(tokens, tagValue[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	if start < len(tagValue) {
+		tokens = append(tokens, tagValue[start:])
+	}
+	return tokens
+}
+
+// lengthRange is an inclusive range of collection lengths.
+type lengthRange struct {
+	min, max int
+}
+
+var defaultLength = lengthRange{min: 1, max: 10}
+
+func parseLength(args string) (lengthRange, error) {
+	minArg, maxArg, found := strings.Cut(args, ";")
+	if !found {
+		return lengthRange{}, fmt.Errorf("len expects min;max got %s", args)
+	}
+	l := defaultLength
+	var err error
+	if minArg != "" {
+		if l.min, err = strconv.Atoi(minArg); err != nil {
+			return lengthRange{}, fmt.Errorf("len min: %w", err)
+		}
+	}
+	if maxArg != "" {
+		if l.max, err = strconv.Atoi(maxArg); err != nil {
+			return lengthRange{}, fmt.Errorf("len max: %w", err)
+		}
+	}
+	return l, l.Validate()
+}
+
+func (l lengthRange) Validate() error {
+	if l.min < 0 {
+		return errors.New("negative len")
+	}
+	if l.min > l.max {
+		return errors.New("len min bigger then max")
+	}
+	return nil
+}
+
+func (l lengthRange) pick(r *rand.Rand) int {
+	return l.min + r.Intn(l.max-l.min+1)
+}
+
+func parseMapLength(args string) (lengthRange, error) {
+	length, found := strings.CutPrefix(args, "len=")
+	if !found {
+		return lengthRange{}, fmt.Errorf("map expects len=min;max got %s", args)
+	}
+	return parseLength(length)
+}
+
+func parseNilChance(args string) (float64, error) {
+	chance, err := strconv.ParseFloat(args, 64)
+	if err != nil {
+		return 0, fmt.Errorf("nil chance: %w", err)
+	}
+	if chance < 0 || chance > 1 {
+		return 0, fmt.Errorf("nil chance %v not between 0 and 1", chance)
+	}
+	return chance, nil
+}
+
+// getArgs splits option[a;b;c] into its arguments, returning none for a bare
+// option.
+func getArgs(value, option string) ([]string, error) {
+	rest := strings.TrimPrefix(value, option)
+	if rest == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") {
+		return nil, fmt.Errorf("option not available %s", value)
+	}
+	return strings.Split(rest[1:len(rest)-1], ";"), nil
+}
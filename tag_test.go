@@ -0,0 +1,70 @@
+package gomaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTag(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		arg  string
+		gen  string
+		mods map[string]string
+		err  string
+	}{
+		{"generator only", "rand[1;10;1]", "rand[1;10;1]", map[string]string{}, ""},
+		{"modifier only", "len[1;3]", "", map[string]string{"len": "1;3"}, ""},
+		{"both", "rand[1;10;1] len[1;3]", "rand[1;10;1]", map[string]string{"len": "1;3"}, ""},
+		{"spaces in regex", `regex[[a-z ]{2} \]] len[;4]`, `regex[[a-z ]{2} \]]`, map[string]string{"len": ";4"}, ""},
+		{"two generators", "rand regex[a]", "", nil, "multiple generators rand regex[a]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTag(tt.arg)
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+				return
+			}
+			if got.gen != tt.gen {
+				t.Errorf("parseTag() gen = %v, want %v", got.gen, tt.gen)
+			}
+			if !reflect.DeepEqual(got.mods, tt.mods) {
+				t.Errorf("parseTag() mods = %v, want %v", got.mods, tt.mods)
+			}
+		})
+	}
+}
+
+func Test_parseLength(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		arg  string
+		want lengthRange
+		err  string
+	}{
+		{"full", "2;5", lengthRange{min: 2, max: 5}, ""},
+		{"max only", ";3", lengthRange{min: 1, max: 3}, ""},
+		{"min only", "4;", lengthRange{min: 4, max: 10}, ""},
+		{"no separator", "4", lengthRange{}, "len expects min;max got 4"},
+		{"negative", "-1;2", lengthRange{}, "negative len"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLength(tt.arg)
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
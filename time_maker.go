@@ -0,0 +1,280 @@
+package gomaker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationUnit = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w)`)
+)
+
+var (
+	defaultTimeMin = timeBound{offset: -365 * 24 * time.Hour, relative: true}
+	defaultTimeMax = timeBound{relative: true}
+)
+
+// timeBound is either an absolute instant or an offset from the Maker's now.
+type timeBound struct {
+	at       time.Time
+	offset   time.Duration
+	relative bool
+}
+
+func (b timeBound) resolve(now time.Time) time.Time {
+	if b.relative {
+		return now.Add(b.offset)
+	}
+	return b.at
+}
+
+type timeOptions struct {
+	min, max timeBound
+	loc      *time.Location
+	trunc    time.Duration
+}
+
+// compileTime parses time[min;max;tz=...;trunc=...]. Bounds are RFC3339
+// instants, dates, now or offsets from now such as -30d, and default to the
+// last year. Times are generated in UTC unless tz names another location, and
+// trunc truncates on the wall clock of that location, keeping within bounds.
+func compileTime(tagValue string, typeOf reflect.Type, now func() time.Time) (fillFunc, error) {
+	if !typeOf.ConvertibleTo(timeType) {
+		return nil, fmt.Errorf("kind not supported: %s", typeOf.Kind().String())
+	}
+	o, err := getTimeOptions(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		t, err := randTime(r, o, now())
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t).Convert(field.Type()))
+		return nil
+	}, nil
+}
+
+func getTimeOptions(value string) (timeOptions, error) {
+	o := timeOptions{min: defaultTimeMin, max: defaultTimeMax, loc: time.UTC}
+	args, err := getArgs(value, string(tm))
+	if err != nil {
+		return timeOptions{}, err
+	}
+	for i, arg := range args {
+		key, val, isOption := strings.Cut(arg, "=")
+		switch {
+		case !isOption && i == 0 && arg != "":
+			o.min, err = parseTimeBound(arg)
+		case !isOption && i == 1 && arg != "":
+			o.max, err = parseTimeBound(arg)
+		case !isOption && i < 2:
+		case key == "tz":
+			o.loc, err = time.LoadLocation(val)
+		case key == "trunc":
+			o.trunc, err = parseDuration(val)
+		default:
+			err = fmt.Errorf("time option not available %s", arg)
+		}
+		if err != nil {
+			return timeOptions{}, err
+		}
+	}
+	if !o.min.relative && !o.max.relative && o.min.at.After(o.max.at) ||
+		o.min.relative && o.max.relative && o.min.offset > o.max.offset {
+		return timeOptions{}, errors.New("time min after max")
+	}
+	if !o.min.relative && !o.max.relative && o.trunc > 0 {
+		if _, _, err := truncRange(o.min.at, o.max.at, o.trunc, o.loc); err != nil {
+			return timeOptions{}, err
+		}
+	}
+	return o, nil
+}
+
+func parseTimeBound(value string) (timeBound, error) {
+	if value == "now" {
+		return timeBound{relative: true}, nil
+	}
+	if value[0] == '-' || value[0] == '+' {
+		d, err := parseDuration(value)
+		if err != nil {
+			return timeBound{}, err
+		}
+		return timeBound{offset: d, relative: true}, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return timeBound{at: t}, nil
+		}
+	}
+	return timeBound{}, fmt.Errorf("time bound not recognized %s", value)
+}
+
+func randTime(r *rand.Rand, o timeOptions, now time.Time) (time.Time, error) {
+	min, max := o.min.resolve(now), o.max.resolve(now)
+	if min.After(max) {
+		return time.Time{}, errors.New("time min after max")
+	}
+	if o.trunc <= 0 {
+		return min.Add(randDuration(r, max.Sub(min))).In(o.loc), nil
+	}
+	first, last, err := truncRange(min, max, o.trunc, o.loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// every instant before the one following last truncates into [first, last]
+	t := first.Add(randDuration(r, nextTrunc(last, o.trunc, o.loc).Sub(first)-1))
+	return truncIn(t, o.trunc, o.loc), nil
+}
+
+// truncRange returns the first and last instants in [min, max] truncation to
+// d in loc yields, failing when there are none.
+func truncRange(min, max time.Time, d time.Duration, loc *time.Location) (time.Time, time.Time, error) {
+	first, last := truncIn(min, d, loc), truncIn(max, d, loc)
+	if first.Before(min) {
+		first = nextTrunc(first, d, loc)
+	}
+	if first.After(last) {
+		return time.Time{}, time.Time{}, fmt.Errorf("time trunc %s has no instant between min and max", d)
+	}
+	return first, last, nil
+}
+
+// truncIn truncates t to a multiple of d on the wall clock of loc, so days
+// start at local midnight and weeks on Monday.
+func truncIn(t time.Time, d time.Duration, loc *time.Location) time.Time {
+	return fromWall(wallClock(t.In(loc)).Truncate(d), loc)
+}
+
+// nextTrunc returns the truncated instant following the truncated instant t.
+func nextTrunc(t time.Time, d time.Duration, loc *time.Location) time.Time {
+	return fromWall(wallClock(t.In(loc)).Truncate(d).Add(d), loc)
+}
+
+// wallClock returns the date and clock of t as if t were in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func fromWall(wall time.Time, loc *time.Location) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+}
+
+// randDuration returns a duration in [0, span].
+func randDuration(r *rand.Rand, span time.Duration) time.Duration {
+	if span <= 0 {
+		return 0
+	}
+	if span == 1<<63-1 {
+		return time.Duration(r.Int63())
+	}
+	return time.Duration(r.Int63n(int64(span) + 1))
+}
+
+type durationOptions struct {
+	min, max, step time.Duration
+}
+
+// compileDuration parses dur[min;max;step] with Go durations extended by the
+// d and w units, e.g. dur[1s;5m] or dur[1d;4w;24h].
+func compileDuration(tagValue string, typeOf reflect.Type) (fillFunc, error) {
+	if kind := typeOf.Kind(); kind != reflect.Int64 {
+		return nil, fmt.Errorf("kind not supported: %s", kind.String())
+	}
+	o, err := getDurationOptions(tagValue)
+	if err != nil {
+		return nil, err
+	}
+	return func(r *rand.Rand, _ *scope, field reflect.Value) error {
+		d := o.min + randDuration(r, o.max-o.min)
+		if o.step > 0 {
+			d -= (d - o.min) % o.step
+		}
+		field.SetInt(int64(d))
+		return nil
+	}, nil
+}
+
+func getDurationOptions(value string) (durationOptions, error) {
+	o := durationOptions{max: time.Minute}
+	args, err := getArgs(value, string(dur))
+	if err != nil {
+		return o, err
+	}
+	if len(args) > 3 {
+		return o, fmt.Errorf("dur expects at most 3 arguments got %d", len(args))
+	}
+	bounds := []*time.Duration{&o.min, &o.max, &o.step}
+	for i, arg := range args {
+		if arg == "" {
+			continue
+		}
+		d, err := parseDuration(arg)
+		if err != nil {
+			return o, err
+		}
+		*bounds[i] = d
+	}
+	if o.min > o.max {
+		return o, errors.New("dur min bigger then max")
+	}
+	if o.step < 0 {
+		return o, errors.New("negative step")
+	}
+	return o, nil
+}
+
+// parseDuration is time.ParseDuration accepting d for days and w for weeks.
+func parseDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+	rest := value
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	} else {
+		rest = strings.TrimPrefix(rest, "+")
+	}
+	if rest == "0" {
+		return 0, nil
+	}
+	matches := durationUnit.FindAllStringSubmatchIndex(rest, -1)
+	if len(matches) == 0 || matches[0][0] != 0 {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+	var total time.Duration
+	end := 0
+	for _, match := range matches {
+		if match[0] != end {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+		end = match[1]
+		n, err := strconv.ParseFloat(rest[match[2]:match[3]], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+		var unit time.Duration
+		switch rest[match[4]:match[5]] {
+		case "d":
+			unit = 24 * time.Hour
+		case "w":
+			unit = 7 * 24 * time.Hour
+		default:
+			if unit, err = time.ParseDuration("1" + rest[match[4]:match[5]]); err != nil {
+				return 0, err
+			}
+		}
+		total += time.Duration(n * float64(unit))
+	}
+	if end != len(rest) {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+	return sign * total, nil
+}
@@ -0,0 +1,126 @@
+package gomaker
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func Test_parseDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		arg  string
+		want time.Duration
+		err  bool
+	}{
+		{"go duration", "1h30m", 90 * time.Minute, false},
+		{"days", "-30d", -30 * 24 * time.Hour, false},
+		{"weeks and days", "+1w2d", 9 * 24 * time.Hour, false},
+		{"fraction", "1.5s", 1500 * time.Millisecond, false},
+		{"zero", "0", 0, false},
+		{"garbage", "1x", 0, true},
+		{"trailing", "1dx", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDuration(tt.arg)
+			if (err != nil) != tt.err {
+				t.Fatalf("parseDuration() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getTimeOptions(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		arg      string
+		min, max time.Time
+		err      string
+	}{
+		{"default", "time", now.AddDate(0, 0, -365), now, ""},
+		{"absolute", "time[2020-01-01T00:00:00Z;2021-01-01]", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), ""},
+		{"relative", "time[-30d;now]", now.AddDate(0, 0, -30), now, ""},
+		{"max only", "time[;+1d;tz=UTC]", now.AddDate(0, 0, -365), now.AddDate(0, 0, 1), ""},
+		{"reversed", "time[now;-1d]", time.Time{}, time.Time{}, "time min after max"},
+		{"unknown option", "time[;;foo=bar]", time.Time{}, time.Time{}, "time option not available foo=bar"},
+		{"bad bound", "time[yesterday;now]", time.Time{}, time.Time{}, "time bound not recognized yesterday"},
+		{"no trunc instant", "time[2024-05-10T10:30:00Z;2024-05-10T10:45:00Z;trunc=1h]", time.Time{}, time.Time{}, "time trunc 1h0m0s has no instant between min and max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getTimeOptions(tt.arg)
+			if err != nil {
+				if err.Error() != tt.err {
+					t.Fatalf("expected: %v, got: %v", tt.err, err)
+				}
+				return
+			}
+			if min := got.min.resolve(now); !min.Equal(tt.min) {
+				t.Errorf("getTimeOptions() min = %v, want %v", min, tt.min)
+			}
+			if max := got.max.resolve(now); !max.Equal(tt.max) {
+				t.Errorf("getTimeOptions() max = %v, want %v", max, tt.max)
+			}
+		})
+	}
+}
+
+func Test_randTime_trunc(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		arg   string
+		check func(time.Time) bool
+	}{
+		{"time[2024-03-01;2024-03-31;tz=America/New_York;trunc=24h]", func(v time.Time) bool {
+			return v.Location().String() == "America/New_York" && v.Hour() == 0 && v.Minute() == 0
+		}},
+		{"time[2024-03-01;2024-03-31;tz=America/New_York;trunc=1h]", func(v time.Time) bool {
+			return v.Minute() == 0 && v.Second() == 0
+		}},
+		{"time[2024-01-01;2024-12-31;trunc=7d]", func(v time.Time) bool {
+			return v.Weekday() == time.Monday && v.Hour() == 0
+		}},
+		{"time[2024-05-10T10:30:00Z;2024-05-10T11:45:00Z;trunc=1h]", func(v time.Time) bool {
+			return v.Equal(time.Date(2024, 5, 10, 11, 0, 0, 0, time.UTC))
+		}},
+		{"time[2024-05-10T10:00:00Z;2024-05-10T12:00:00Z;trunc=1h]", func(v time.Time) bool {
+			return v.Minute() == 0 && !v.Before(time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)) &&
+				!v.After(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			o, err := getTimeOptions(tt.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rand.New(rand.NewSource(6))
+			for i := 0; i < 500; i++ {
+				v, err := randTime(r, o, now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !tt.check(v) {
+					t.Fatalf("unexpected time %v", v)
+				}
+			}
+		})
+	}
+
+	o, err := getTimeOptions("time[-10m;now;trunc=1h]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := randTime(rand.New(rand.NewSource(6)), o, now.Add(30*time.Minute)); err == nil ||
+		err.Error() != "time trunc 1h0m0s has no instant between min and max" {
+		t.Errorf("expected: time trunc 1h0m0s has no instant between min and max, got: %v", err)
+	}
+}